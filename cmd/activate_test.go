@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"nimiq-validator-activator/rpc"
+	"testing"
+	"time"
+)
+
+// pendingNode never confirms transactions; a sent activation lands once landAfter validator lookups have passed
+type pendingNode struct {
+	mockNode
+	landAfter int
+	lookups   int
+	landed    bool
+}
+
+func (n *pendingNode) GetTransactionByHash(hash string) (*rpc.Transaction, error) {
+	return &rpc.Transaction{Hash: hash}, nil
+}
+
+func (n *pendingNode) SendNewValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error) {
+	n.sent = append(n.sent, "sendNewValidatorTransaction")
+	return "raw-new-validator-tx", nil
+}
+
+func (n *pendingNode) GetValidatorByAddress(address string) (*rpc.ValidatorDetails, error) {
+	n.lookups++
+	if len(n.sent) > 0 && n.lookups > n.landAfter {
+		n.landed = true
+		return &rpc.ValidatorDetails{Address: address}, nil
+	}
+	return nil, rpc.ErrValidatorNotFound
+}
+
+// setupActivation writes key files for address and configures a fast fee-bumping activation
+func setupActivation(t *testing.T, address string) {
+	t.Helper()
+	keysDir = t.TempDir()
+	writeKeyFileAt(t, keyPath("signing_key.txt"), "Private Key: 1111\n")
+	writeKeyFileAt(t, keyPath("vote_key.txt"), "# Secret Key:\n00ff\n")
+	writeKeyFileAt(t, keyPath("address.txt"), "Address: "+address+"\nPrivate Key: 2222\n")
+	setLeader(true)
+	dryRun = false
+	senderAddress = ""
+	txSigningMode = "node"
+	minStakeNIM = 1
+	txFeeLuna = 500
+	maxFeeLuna = 100000
+	feeBumpFactor = 2
+	feeBumpMaxAttempts = 3
+	confirmationTimeout = 20 * time.Millisecond
+	confirmationPollInterval = time.Millisecond
+	chainAdvanceCheckInterval = 0
+}
+
+func TestActivateValidatorFeeBump(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	tests := []struct {
+		name      string
+		landAfter int
+		wantSends int
+		wantErr   bool
+	}{
+		// The first transaction lands after the confirmation timeout, the guard before the resend sees it
+		{"landed late", 1, 1, false},
+		// Nothing lands, every allowed bump is sent
+		{"never lands", 100, 4, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupActivation(t, address)
+			node := &pendingNode{mockNode: mockNode{consensus: true, address: address, balance: 10 * lunaPerNIM}, landAfter: test.landAfter}
+			err := activateValidator(context.Background(), node, address)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("activateValidator error = %v, want error %t", err, test.wantErr)
+			}
+			if len(node.sent) != test.wantSends {
+				t.Errorf("sent %d activation transactions, want %d", len(node.sent), test.wantSends)
+			}
+		})
+	}
+}
+
+// TestActivateValidatorCanceled checks that shutting down while waiting for confirmation does not resend at a higher fee
+func TestActivateValidatorCanceled(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	setupActivation(t, address)
+	confirmationTimeout = time.Hour
+	node := &pendingNode{mockNode: mockNode{consensus: true, address: address, balance: 10 * lunaPerNIM}, landAfter: 100}
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if err := activateValidator(ctx, node, address); !errors.Is(err, context.Canceled) {
+		t.Fatalf("activateValidator error = %v, want context.Canceled", err)
+	}
+	if len(node.sent) != 1 {
+		t.Errorf("sent %d activation transactions, want 1", len(node.sent))
+	}
+}
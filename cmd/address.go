@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"nimiq-validator-activator/prometheus"
+	"os"
+	"strings"
+)
+
+// errNoValidatorAddress is returned when neither the node nor VALIDATOR_ADDRESS provide a validator address
+var errNoValidatorAddress = errors.New("no validator address available: node returned none and VALIDATOR_ADDRESS is unset")
+
+// resolveValidatorAddress determines the validator address, preferring VALIDATOR_ADDRESS over the
+// node's configured address and refusing to guess when the node wallet holds several accounts
+func resolveValidatorAddress(client NimiqRPC) (string, error) {
+	if configuredValidatorAddress != "" {
+		return configuredValidatorAddress, nil
+	}
+
+	address, err := client.GetAddress()
+	if err != nil {
+		// The node may still be loading its wallet at boot, the startup sequence retries this
+		return "", fmt.Errorf("no validator address available: node returned an error and VALIDATOR_ADDRESS is unset: %w", err)
+	}
+	if strings.TrimSpace(address) == "" {
+		return "", errNoValidatorAddress
+	}
+	if err := checkRuntimeAddress("validator", address); err != nil {
+		return "", fmt.Errorf("node validator address is invalid: %w", err)
+	}
+
+	accounts, err := client.ListAccounts()
+	if err != nil {
+		log.Println("Error listing node wallet accounts, using the node's validator address:", err)
+		return address, nil
+	}
+	// Accounts we import ourselves for paying fees do not make the validator ambiguous
+	var others []string
+	for _, account := range accounts {
+		if !sameAddress(account, address) && !sameAddress(account, senderAddress) {
+			others = append(others, account)
+		}
+	}
+	if len(others) > 0 {
+		return "", fmt.Errorf("node wallet holds %d accounts, set VALIDATOR_ADDRESS to choose the validator", len(accounts))
+	}
+
+	return address, nil
+}
+
+// getAddressFromKeyFile returns the address of the first "Address:" line of a key file
+func getAddressFromKeyFile(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "Address:") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Address:")), nil
+		}
+	}
+	return "", fmt.Errorf("address not found in file")
+}
+
+// checkAddressKeyFile verifies the address key file belongs to the validator, so we never import
+// and unlock the key of a different account. Files without an "Address:" line cannot be checked.
+func checkAddressKeyFile(validatorAddress string) error {
+	fileAddress, err := getAddressFromKeyFile(keyPath("address.txt"))
+	if err != nil {
+		log.Println("Skipping address key file check:", err)
+		return nil
+	}
+	if !sameAddress(fileAddress, validatorAddress) {
+		return fmt.Errorf("address key file belongs to %s, not to validator %s", fileAddress, validatorAddress)
+	}
+	return nil
+}
+
+// knownNetworks lists the networks NIMIQ_NETWORK and EXPECTED_NETWORK accept. Addresses carry the
+// NQ prefix on every network, so an address or key cannot tell which network it belongs to.
+var knownNetworks = map[string]bool{
+	"mainnet": true,
+	"testnet": true,
+}
+
+// checkExpectedNetwork verifies that NIMIQ_NETWORK agrees with EXPECTED_NETWORK, guarding against a
+// deployment configured for the wrong network. Neither the node nor the keys can be checked: the
+// node RPC does not report which network it runs on and addresses look the same on every network.
+func checkExpectedNetwork() error {
+	if expectedNetwork == "" {
+		return nil
+	}
+	matches := float64(0)
+	defer func() { prometheus.ActivatorExpectedNetworkMatchGauge.Set(matches) }()
+
+	if network != expectedNetwork {
+		return fmt.Errorf("NIMIQ_NETWORK is %s but EXPECTED_NETWORK is %s", network, expectedNetwork)
+	}
+	matches = 1
+	return nil
+}
+
+// checkRuntimeAddress validates an address learned at runtime, exposing mismatches as a metric
+func checkRuntimeAddress(role, address string) error {
+	if err := validateAddress(address); err != nil {
+		prometheus.ActivatorAddressInvalidGauge.WithLabelValues(role).Set(1)
+		return err
+	}
+	prometheus.ActivatorAddressInvalidGauge.WithLabelValues(role).Set(0)
+	return nil
+}
+
+// nimiqAddressAlphabet is the base32 alphabet used in user-friendly Nimiq addresses
+const nimiqAddressAlphabet = "0123456789ABCDEFGHJKLMNPQRSTUVXY"
+
+// validateAddress checks that a user-friendly Nimiq address (e.g. "NQ07 0000 ...") is well-formed
+func validateAddress(address string) error {
+	compact := strings.ToUpper(strings.ReplaceAll(address, " ", ""))
+	if len(compact) != 36 {
+		return fmt.Errorf("address %q must have 36 characters without spaces", address)
+	}
+	if !strings.HasPrefix(compact, "NQ") {
+		return fmt.Errorf("address %q must start with NQ", address)
+	}
+	for _, c := range compact[2:4] {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("address %q has invalid check digits", address)
+		}
+	}
+	for _, c := range compact[4:] {
+		if !strings.ContainsRune(nimiqAddressAlphabet, c) {
+			return fmt.Errorf("address %q contains invalid character %q", address, c)
+		}
+	}
+
+	// IBAN style checksum: move the country code and check digits to the end and require mod 97 == 1
+	var digits strings.Builder
+	for _, c := range compact[4:] + compact[:4] {
+		if c >= 'A' && c <= 'Z' {
+			digits.WriteString(fmt.Sprint(int(c-'A') + 10))
+		} else {
+			digits.WriteRune(c)
+		}
+	}
+	value, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(value, big.NewInt(97)).Int64() != 1 {
+		return fmt.Errorf("address %q has an invalid checksum", address)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"nimiq-validator-activator/prometheus"
+	"sync"
+	"time"
+)
+
+// balanceMu guards balanceEMA and balanceHistory
+var balanceMu sync.Mutex
+
+// balanceEMA holds the smoothed balance per address
+var balanceEMA = map[string]float64{}
+
+// balanceReading is a balance observed at a point in time
+type balanceReading struct {
+	at      time.Time
+	balance int64
+}
+
+// balanceHistoryLength is how many recent readings the growth rate is computed over
+const balanceHistoryLength = 20
+
+// balanceHistory holds the recent balance readings per address
+var balanceHistory = map[string][]balanceReading{}
+
+// recordBalance updates the raw balance gauge and, when enabled, the smoothed EMA gauge
+func recordBalance(address string, balance int64) {
+	prometheus.ValidatorBalanceGauge.WithLabelValues(address).Set(float64(balance))
+	summary.setBalance(address, balance)
+
+	balanceMu.Lock()
+	defer balanceMu.Unlock()
+	recordBalanceHistory(address, balance)
+
+	if balanceEMAAlpha <= 0 {
+		return
+	}
+	ema, seen := balanceEMA[address]
+	if !seen {
+		ema = float64(balance) // Seed with the first reading
+	} else {
+		ema = balanceEMAAlpha*float64(balance) + (1-balanceEMAAlpha)*ema
+	}
+	balanceEMA[address] = ema
+	prometheus.ValidatorBalanceEMAGauge.WithLabelValues(address).Set(ema)
+}
+
+// recordBalanceHistory keeps the recent readings and exposes the estimated time until the balance
+// covers the validator deposit plus fee, 0 once it does and -1 while the balance is not growing.
+// The caller must hold balanceMu.
+func recordBalanceHistory(address string, balance int64) {
+	history := append(balanceHistory[address], balanceReading{at: time.Now(), balance: balance})
+	if len(history) > balanceHistoryLength {
+		history = history[len(history)-balanceHistoryLength:]
+	}
+	balanceHistory[address] = history
+
+	prometheus.ValidatorSecondsToThresholdGauge.WithLabelValues(address).Set(secondsToThreshold(history))
+}
+
+// estimatedSecondsToThreshold returns the current time-to-threshold estimate of an address
+func estimatedSecondsToThreshold(address string) float64 {
+	balanceMu.Lock()
+	defer balanceMu.Unlock()
+	return secondsToThreshold(balanceHistory[address])
+}
+
+// secondsToThreshold estimates when the balance reaches the threshold from its growth over the readings
+func secondsToThreshold(history []balanceReading) float64 {
+	if len(history) == 0 {
+		return -1
+	}
+	latest := history[len(history)-1]
+	deficit := float64(int64(minStakeNIM*lunaPerNIM)+int64(txFeeLuna)) - float64(latest.balance)
+	if deficit <= 0 {
+		return 0
+	}
+	oldest := history[0]
+	elapsed := latest.at.Sub(oldest.at).Seconds()
+	if elapsed <= 0 {
+		return -1 // A single reading has no growth rate yet
+	}
+	rate := float64(latest.balance-oldest.balance) / elapsed
+	if rate <= 0 {
+		return -1
+	}
+	return deficit / rate
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestConcurrentTrackingState updates the shared tracking state from several goroutines at once.
+// Run with -race to catch unguarded access.
+func TestConcurrentTrackingState(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	balanceEMAAlpha = 0.5
+	maxIterations, maxRuntime = 0, 0
+	beginIterationSummary(&mockNode{}, address)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				recordBalance(address, int64(worker*1000+i))
+				estimatedSecondsToThreshold(address)
+				summary.setState("active")
+				summary.setAction("none")
+				summary.lastKnownState()
+				recordUnlock(address)
+				updateUnlockExpiryGauges()
+				recordJailEvent(address, i)
+				isJailEscalated()
+				runLimitReached()
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if got := loopIterations.Load(); got < 800 {
+		t.Fatalf("loopIterations = %d, want at least 800", got)
+	}
+	if !summary.hasBalance {
+		t.Fatal("summary did not record the balance of the summarized address")
+	}
+}
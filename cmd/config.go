@@ -0,0 +1,607 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"nimiq-validator-activator/faucet"
+	"nimiq-validator-activator/logging"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	faucetURL    string
+	faucetClient faucet.Faucet
+	network      string
+	nimiqNodeUrl string
+	servingPort  string
+	keysDir      string
+	userAgent    string
+
+	// Endpoint lifecycle events are posted to when set, and the timeout of each delivery
+	webhookURL     string
+	webhookTimeout time.Duration
+
+	// Network the keys and node must belong to, startup is refused on any mismatch when set
+	expectedNetwork string
+
+	// Fixture files RPC exchanges are recorded to or replayed from instead of a live node
+	rpcRecordPath string
+	rpcReplayPath string
+
+	// How key files readable by other users are treated: "strict", "warn" or "off"
+	keyFilePermissions string
+
+	// Bearer token required on the HTTP endpoints when set, /fund is only served with one
+	metricsToken string
+
+	// Minimum time between faucet requests, shared by the poll loop and manual triggers
+	faucetMinInterval time.Duration
+
+	// How long to wait for a faucet funding transaction to confirm when the faucet reports its hash.
+	// Off by default since the wait holds up the funding loop, the next poll sees the funds anyway.
+	faucetConfirmTimeout time.Duration
+
+	// Maximum concurrent RPC requests to the node, 0 for no limit
+	rpcMaxInFlight int
+
+	// Timeouts of the metrics HTTP server
+	metricsReadTimeout  time.Duration
+	metricsWriteTimeout time.Duration
+	metricsIdleTimeout  time.Duration
+
+	// Thresholds, fees and intervals used by the activation lifecycle
+	minStakeNIM           = 100000.0
+	txFeeLuna             = 500
+	jailReleaseBlocks     = 8000
+	blocksPerBatch        = 60
+	fundingPollInterval   = 10 * time.Second
+	pollInterval          = 15 * time.Second
+	unlockDurationSeconds = 0
+	stakeBufferNIM        float64
+
+	// How often the expensive staker list is refetched, cached in between
+	stakersRefreshInterval time.Duration
+
+	// How long to watch the block height before sending a transaction
+	chainAdvanceCheckInterval time.Duration
+
+	// Smoothing factor of the balance EMA, 0 disables it
+	balanceEMAAlpha float64
+
+	// Explicit validator address, required when the node wallet holds several accounts
+	configuredValidatorAddress string
+
+	// Address receiving validator rewards, empty means the validator address
+	rewardAddress string
+
+	// Separate fee-paying sender and its key file, empty means the validator address pays
+	senderAddress string
+	senderKeyFile string
+
+	// Address whose balance gates activation and is funded, empty means the validator address
+	balanceCheckAddress string
+
+	// Staker address whose stake is exported as metrics, empty to disable
+	stakerAddress string
+
+	// Confirmation tracking and fee bumping of activation transactions, a zero timeout disables waiting
+	confirmationTimeout      time.Duration
+	confirmationPollInterval time.Duration
+	confirmationDepth        int // Blocks, including the transaction's own, required before it counts as confirmed
+	feeBumpFactor            float64
+	feeBumpMaxAttempts       int
+	maxFeeLuna               int
+
+	// Opt-in top-ups of the validator from a funding account, capped per period
+	topUpEnabled         bool
+	fundingAddress       string
+	fundingKeyFile       string
+	topUpMaxNIMPerPeriod float64
+	topUpPeriod          time.Duration
+
+	// When false, needed activations are only reported, never executed
+	activationEnabled bool
+
+	// When true, every mutating action is logged and counted instead of executed
+	dryRun bool
+
+	// When false, retired validators are treated as intentionally retired and left alone
+	autoReactivateRetired bool
+
+	// Optional lease file shared between replicas so only one performs mutating actions
+	leaderLeaseFile string
+	leaderLeaseTTL  time.Duration
+
+	// How validator transactions are built: "node" uses the node wallet, "raw" creates, signs and sends separately
+	txSigningMode string
+
+	// Stop after this many loop iterations or this much runtime, 0 runs forever
+	maxIterations int
+	maxRuntime    time.Duration
+
+	// Where a deleted validator is recorded across restarts, and whether to recreate deleted validators anyway
+	validatorStateFile   string
+	allowRecreateDeleted bool
+
+	// Whether /readyz waits for the validator to be active instead of only a working node connection
+	readyRequiresActive bool
+
+	// Log one summary line per main loop iteration and demote per-step logs to debug
+	summaryLog bool
+
+	// Overall time allowed for the startup sequence to succeed
+	startupDeadline time.Duration
+	// What to do when consensus is still missing at the startup deadline: "exit" or "retry"
+	onNoConsensus string
+	// Consecutive established consensus readings, and the wait between them, before consensus counts as stable
+	consensusStableReadings  int
+	consensusReadingInterval time.Duration
+
+	// Repeated jailing within the window stops automatic reactivation
+	jailEscalationThreshold int
+	jailEscalationWindow    time.Duration
+)
+
+// loadConfig resolves all settings from the environment, which a config file may have pre-populated
+func loadConfig() {
+	if err := applyConnectionString(); err != nil {
+		log.Fatalf("Invalid NIMIQ_CONNECTION: %v", err)
+	}
+	servingPort = getServingPort()
+	logging.SetDebug(strings.EqualFold(getEnv("LOG_LEVEL", "info"), "debug"))
+
+	// Fetching faucet URL from environment variable with a default value
+	nimiqNodeUrl = getEnv("NIMIQ_NODE_URL", "http://node:8648")
+
+	// Fetching faucet URL from environment variable with a default value
+	faucetURL = getEnv("FAUCET_URL", "https://faucet.pos.nimiq-testnet.com/tapit")
+	// Identify our traffic to RPC providers and faucets
+	userAgent = getEnv("USER_AGENT", "nimiq-validator-activator/"+appVersion)
+	// Keep bursts from batching and confirmation polling from overwhelming small nodes
+	rpcMaxInFlight = getEnvInt("NIMIQ_RPC_MAX_INFLIGHT", 4)
+	rpcRecordPath = getEnv("RPC_RECORD", "")
+	rpcReplayPath = getEnv("RPC_REPLAY", "")
+
+	httpFaucet := faucet.NewHTTPFaucet(faucetURL)
+	httpFaucet.UserAgent = userAgent
+	httpFaucet.HTTPClient.Timeout = getEnvDuration("FAUCET_TIMEOUT", faucet.DefaultTimeout)
+	if httpFaucet.HTTPClient.Timeout <= 0 {
+		log.Fatalf("Invalid FAUCET_TIMEOUT %s, expected a positive duration", httpFaucet.HTTPClient.Timeout)
+	}
+	// Only faucets with anti-abuse challenges need a token fetched before each funding request
+	httpFaucet.TokenURL = getEnv("FAUCET_TOKEN_URL", "")
+	httpFaucet.TokenHeader = getEnv("FAUCET_TOKEN_HEADER", httpFaucet.TokenHeader)
+	// Faucets accepting an amount can fund the stake in fewer, larger requests
+	httpFaucet.Amount = int64(getEnvFloat("FAUCET_AMOUNT", 0) * lunaPerNIM)
+	faucetClient = httpFaucet
+
+	// Fetching network type from environment variable with a default value
+	network = getEnv("NIMIQ_NETWORK", "testnet")
+	expectedNetwork = getEnv("EXPECTED_NETWORK", "")
+	if expectedNetwork != "" && !knownNetworks[expectedNetwork] {
+		log.Fatalf("Invalid EXPECTED_NETWORK %q, expected mainnet or testnet", expectedNetwork)
+	}
+
+	keysDir = getEnv("KEYS_DIR", "/keys")
+	keyFilePermissions = getEnv("KEY_FILE_PERMISSIONS", "warn")
+	if keyFilePermissions != "strict" && keyFilePermissions != "warn" && keyFilePermissions != "off" {
+		log.Fatalf("Invalid KEY_FILE_PERMISSIONS %q, expected strict, warn or off", keyFilePermissions)
+	}
+	metricsToken = getEnv("METRICS_TOKEN", "")
+	webhookURL = getEnv("WEBHOOK_URL", "")
+	webhookTimeout = getEnvDuration("WEBHOOK_TIMEOUT", 5*time.Second)
+	faucetConfirmTimeout = getEnvDuration("FAUCET_CONFIRM_TIMEOUT", 0)
+	faucetMinInterval = getEnvDuration("FAUCET_MIN_INTERVAL", 5*time.Second)
+	metricsReadTimeout = getEnvDuration("METRICS_READ_TIMEOUT", 10*time.Second)
+	metricsWriteTimeout = getEnvDuration("METRICS_WRITE_TIMEOUT", 10*time.Second)
+	metricsIdleTimeout = getEnvDuration("METRICS_IDLE_TIMEOUT", 60*time.Second)
+	balanceEMAAlpha = getEnvFloat("BALANCE_EMA_ALPHA", 0)
+	if balanceEMAAlpha < 0 || balanceEMAAlpha > 1 {
+		log.Printf("BALANCE_EMA_ALPHA must be between 0 and 1, disabling balance EMA")
+		balanceEMAAlpha = 0
+	}
+	stakersRefreshInterval = getEnvDuration("STAKERS_REFRESH_INTERVAL", 60*time.Second)
+	chainAdvanceCheckInterval = getEnvDuration("CHAIN_ADVANCE_CHECK_INTERVAL", 3*time.Second)
+	configuredValidatorAddress = getEnv("VALIDATOR_ADDRESS", "")
+	if configuredValidatorAddress != "" {
+		if err := validateAddress(configuredValidatorAddress); err != nil {
+			log.Fatalf("Invalid VALIDATOR_ADDRESS: %v", err)
+		}
+	}
+	rewardAddress = getEnv("REWARD_ADDRESS", "")
+	if rewardAddress != "" {
+		if err := validateAddress(rewardAddress); err != nil {
+			log.Fatalf("Invalid REWARD_ADDRESS: %v", err)
+		}
+	}
+	senderAddress = getEnv("SENDER_ADDRESS", "")
+	if senderAddress != "" {
+		if err := validateAddress(senderAddress); err != nil {
+			log.Fatalf("Invalid SENDER_ADDRESS: %v", err)
+		}
+	}
+	balanceCheckAddress = getEnv("BALANCE_CHECK_ADDRESS", "")
+	if balanceCheckAddress != "" {
+		if err := validateAddress(balanceCheckAddress); err != nil {
+			log.Fatalf("Invalid BALANCE_CHECK_ADDRESS: %v", err)
+		}
+	}
+	stakerAddress = getEnv("STAKER_ADDRESS", "")
+	if stakerAddress != "" {
+		if err := validateAddress(stakerAddress); err != nil {
+			log.Fatalf("Invalid STAKER_ADDRESS: %v", err)
+		}
+	}
+	senderKeyFile = getEnv("SENDER_KEY_FILE", keyPath("sender.txt"))
+	confirmationTimeout = getEnvDuration("CONFIRMATION_TIMEOUT", 0)
+	pollInterval = getEnvDuration(envKey("NIMIQ_POLL_INTERVAL", "POLL_INTERVAL"), pollInterval)
+	fundingPollInterval = getEnvDuration(envKey("NIMIQ_FUNDING_POLL_INTERVAL", "FUNDING_POLL_INTERVAL"), fundingPollInterval)
+	if pollInterval <= 0 || fundingPollInterval <= 0 {
+		log.Fatalf("NIMIQ_POLL_INTERVAL and NIMIQ_FUNDING_POLL_INTERVAL must be positive, got %s and %s", pollInterval, fundingPollInterval)
+	}
+	confirmationPollInterval = getEnvDuration("CONFIRMATION_POLL_INTERVAL", 2*time.Second)
+	confirmationDepth = getEnvInt("CONFIRMATION_DEPTH", 1)
+	if confirmationDepth < 1 {
+		log.Fatalf("Invalid CONFIRMATION_DEPTH %d, expected at least 1", confirmationDepth)
+	}
+	feeBumpFactor = getEnvFloat("FEE_BUMP_FACTOR", 1.5)
+	feeBumpMaxAttempts = getEnvInt("FEE_BUMP_MAX_ATTEMPTS", 0)
+	maxFeeLuna = getEnvInt("MAX_FEE_LUNA", 5000)
+	txFeeLuna = getEnvInt("TX_FEE_LUNA", txFeeLuna)
+	if txFeeLuna < 0 {
+		log.Fatalf("Invalid TX_FEE_LUNA %d, expected a non-negative fee", txFeeLuna)
+	}
+	jailReleaseBlocks = getEnvInt("JAIL_RELEASE_BLOCKS", jailReleaseBlocks)
+	if jailReleaseBlocks < 1 {
+		log.Fatalf("Invalid JAIL_RELEASE_BLOCKS %d, expected at least 1", jailReleaseBlocks)
+	}
+	// Epoch rewards are paid at the macro block ending every batch
+	blocksPerBatch = getEnvInt("BLOCKS_PER_BATCH", blocksPerBatch)
+	if blocksPerBatch < 1 {
+		log.Fatalf("Invalid BLOCKS_PER_BATCH %d, expected at least 1", blocksPerBatch)
+	}
+	// Accounts stay unlocked until the node restarts unless UNLOCK_DURATION bounds the unlock
+	unlockDuration := getEnvDuration("UNLOCK_DURATION", time.Duration(unlockDurationSeconds)*time.Second)
+	if unlockDuration < 0 {
+		log.Fatalf("Invalid UNLOCK_DURATION %s, expected 0 or a positive duration", unlockDuration)
+	}
+	unlockDurationSeconds = int(unlockDuration.Seconds())
+	minStake := getEnv("MIN_VALIDATOR_STAKE", strconv.FormatFloat(minStakeNIM, 'f', -1, 64))
+	parsedMinStake, err := strconv.ParseFloat(minStake, 64)
+	if err != nil || parsedMinStake < 0 || math.IsInf(parsedMinStake, 0) || math.IsNaN(parsedMinStake) {
+		log.Fatalf("Invalid MIN_VALIDATOR_STAKE %q, expected a non-negative amount of NIM", minStake)
+	}
+	minStakeNIM = parsedMinStake
+	stakeBufferNIM = getEnvFloat("STAKE_BUFFER_NIM", 0)
+	topUpEnabled = getEnvBool("TOPUP_ENABLED", false)
+	fundingAddress = getEnv("FUNDING_ADDRESS", "")
+	fundingKeyFile = getEnv("FUNDING_KEY_FILE", keyPath("funding.txt"))
+	topUpMaxNIMPerPeriod = getEnvFloat("TOPUP_MAX_NIM_PER_PERIOD", 1000)
+	topUpPeriod = getEnvDuration("TOPUP_PERIOD", 24*time.Hour)
+	if topUpEnabled {
+		if err := validateAddress(fundingAddress); err != nil {
+			log.Fatalf("TOPUP_ENABLED requires a valid FUNDING_ADDRESS: %v", err)
+		}
+	}
+	txSigningMode = getEnv("TX_SIGNING_MODE", "node")
+	if txSigningMode != "node" && txSigningMode != "raw" {
+		log.Fatalf("Invalid TX_SIGNING_MODE %q, expected node or raw", txSigningMode)
+	}
+	activationEnabled = getEnvBool("ACTIVATION_ENABLED", true)
+	dryRun = getEnvBool("DRY_RUN", false)
+	autoReactivateRetired = getEnvBool("AUTO_REACTIVATE_RETIRED", true)
+	// Replicas sharing LEADER_LEASE_FILE coordinate through flock on "<file>.lock", which must be on a filesystem supporting it
+	leaderLeaseFile = getEnv("LEADER_LEASE_FILE", "")
+	leaderLeaseTTL = getEnvDuration("LEADER_LEASE_TTL", 30*time.Second)
+	validatorStateFile = getEnv("VALIDATOR_STATE_FILE", "")
+	allowRecreateDeleted = getEnvBool("ALLOW_RECREATE_DELETED", false)
+	maxIterations = getEnvInt("MAX_ITERATIONS", 0)
+	maxRuntime = getEnvDuration("MAX_RUNTIME", 0)
+	readyRequiresActive = getEnvBool("READY_REQUIRES_ACTIVE", false)
+	summaryLog = getEnvBool("SUMMARY_LOG", false)
+	startupDeadline = getEnvDuration("STARTUP_DEADLINE", 5*time.Minute)
+	onNoConsensus = getEnv("ON_NO_CONSENSUS", "exit")
+	if onNoConsensus != "exit" && onNoConsensus != "retry" {
+		log.Fatalf("Invalid ON_NO_CONSENSUS %q, expected exit or retry", onNoConsensus)
+	}
+	consensusStableReadings = getEnvInt("CONSENSUS_STABLE_READINGS", 3)
+	if consensusStableReadings < 1 {
+		log.Fatalf("Invalid CONSENSUS_STABLE_READINGS %d, expected at least 1", consensusStableReadings)
+	}
+	consensusReadingInterval = getEnvDuration("CONSENSUS_READING_INTERVAL", 5*time.Second)
+	jailEscalationThreshold = getEnvInt("JAIL_ESCALATION_THRESHOLD", 3)
+	jailEscalationWindow = getEnvDuration("JAIL_ESCALATION_WINDOW", 24*time.Hour)
+
+	log.Printf("Nimiq Node URL: %s", nimiqNodeUrl)
+	log.Printf("Faucet URL: %s", faucetURL)
+	log.Printf("Network: %s", network)
+	logEffectiveConfig()
+}
+
+// getEnv reads a string from the environment, falling back to def when unset
+func getEnv(key, def string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		value = def
+	}
+	recordSetting(key, value)
+	return value
+}
+
+// envKey returns the environment variable to read a setting from: key, or the older name legacy
+// when only that one is set
+func envKey(key, legacy string) string {
+	if os.Getenv(key) == "" && os.Getenv(legacy) != "" {
+		return legacy
+	}
+	return key
+}
+
+// getEnvInt reads an integer from the environment, falling back to def when unset or invalid
+func getEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		recordSetting(key, def)
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %d", value, key, def)
+		parsed = def
+	}
+	recordSetting(key, parsed)
+	return parsed
+}
+
+// getEnvDuration reads a duration (e.g. "30s") from the environment, falling back to def when unset or invalid
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		recordSetting(key, def)
+		return def
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %s", value, key, def)
+		parsed = def
+	}
+	recordSetting(key, parsed)
+	return parsed
+}
+
+// getEnvBool reads a boolean (true/false, 1/0) from the environment, falling back to def when unset or invalid
+func getEnvBool(key string, def bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		recordSetting(key, def)
+		return def
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %t", value, key, def)
+		parsed = def
+	}
+	recordSetting(key, parsed)
+	return parsed
+}
+
+// getEnvFloat reads a floating point number from the environment, falling back to def when unset or invalid
+func getEnvFloat(key string, def float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		recordSetting(key, def)
+		return def
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid value %q for %s, using default %g", value, key, def)
+		parsed = def
+	}
+	recordSetting(key, parsed)
+	return parsed
+}
+
+// effectiveSettings holds the resolved value of every setting read, keyed by environment variable
+var effectiveSettings = map[string]string{}
+
+func recordSetting(key string, value interface{}) {
+	effectiveSettings[key] = fmt.Sprint(value)
+}
+
+// isSecretSetting reports whether a setting holds credentials that must not be printed.
+// Webhook URLs carry their token in the path, so anyone knowing one can post to the channel.
+func isSecretSetting(key string) bool {
+	return strings.HasSuffix(key, "_PASSWORD") || strings.HasSuffix(key, "_TOKEN") || strings.HasSuffix(key, "_SECRET") || key == "WEBHOOK_URL"
+}
+
+// displaySetting returns the value of a setting as it may be logged: secrets are redacted and
+// URL-valued settings lose any user:password@ credentials
+func displaySetting(key, value string) string {
+	if value == "" {
+		return value
+	}
+	if isSecretSetting(key) {
+		return "[REDACTED]"
+	}
+	if !strings.HasSuffix(key, "_URL") {
+		return value
+	}
+	// Node URLs may omit the scheme, which url.Parse would take the username for
+	raw := value
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "[UNPARSABLE URL]"
+	}
+	if parsed.User == nil {
+		return value
+	}
+	parsed.User = nil
+	if raw != value {
+		return strings.TrimPrefix(parsed.String(), "http://")
+	}
+	return parsed.String()
+}
+
+// logEffectiveConfig prints every resolved setting for auditability, with secrets redacted
+func logEffectiveConfig() {
+	keys := make([]string, 0, len(effectiveSettings))
+	for key := range effectiveSettings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	log.Printf("Effective configuration:")
+	for _, key := range keys {
+		log.Printf("  %s=%s", key, displaySetting(key, effectiveSettings[key]))
+	}
+}
+
+// fileConfig is the YAML config file layout, each field maps to the environment variable in its env tag
+type fileConfig struct {
+	Connection     *string  `yaml:"connection" env:"NIMIQ_CONNECTION"`
+	NodeURL        *string  `yaml:"node_url" env:"NIMIQ_NODE_URL"`
+	NodeURLExact   *bool    `yaml:"node_url_verbatim" env:"NIMIQ_NODE_URL_VERBATIM"`
+	Network        *string  `yaml:"network" env:"NIMIQ_NETWORK"`
+	ExpectedNet    *string  `yaml:"expected_network" env:"EXPECTED_NETWORK"`
+	KeyFilePerms   *string  `yaml:"key_file_permissions" env:"KEY_FILE_PERMISSIONS"`
+	RPCMaxInFlight *int     `yaml:"rpc_max_inflight" env:"NIMIQ_RPC_MAX_INFLIGHT"`
+	RPCTimeout     *string  `yaml:"rpc_timeout" env:"NIMIQ_RPC_TIMEOUT"`
+	RPCUser        *string  `yaml:"rpc_user" env:"NIMIQ_RPC_USER"`
+	RPCPassword    *string  `yaml:"rpc_password" env:"NIMIQ_RPC_PASSWORD"`
+	RPCRecord      *string  `yaml:"rpc_record" env:"RPC_RECORD"`
+	RPCReplay      *string  `yaml:"rpc_replay" env:"RPC_REPLAY"`
+	MetricsToken   *string  `yaml:"metrics_token" env:"METRICS_TOKEN"`
+	WebhookURL     *string  `yaml:"webhook_url" env:"WEBHOOK_URL"`
+	WebhookTimeout *string  `yaml:"webhook_timeout" env:"WEBHOOK_TIMEOUT"`
+	FaucetInterval *string  `yaml:"faucet_min_interval" env:"FAUCET_MIN_INTERVAL"`
+	FaucetConfirm  *string  `yaml:"faucet_confirm_timeout" env:"FAUCET_CONFIRM_TIMEOUT"`
+	FaucetURL      *string  `yaml:"faucet_url" env:"FAUCET_URL"`
+	FaucetTimeout  *string  `yaml:"faucet_timeout" env:"FAUCET_TIMEOUT"`
+	FaucetToken    *string  `yaml:"faucet_token_url" env:"FAUCET_TOKEN_URL"`
+	FaucetAmount   *float64 `yaml:"faucet_amount" env:"FAUCET_AMOUNT"`
+	FaucetHeader   *string  `yaml:"faucet_token_header" env:"FAUCET_TOKEN_HEADER"`
+	PrometheusPort *int     `yaml:"prometheus_port" env:"PROMETHEUS_PORT"`
+	LogLevel       *string  `yaml:"log_level" env:"LOG_LEVEL"`
+	UserAgent      *string  `yaml:"user_agent" env:"USER_AGENT"`
+	KeysDir        *string  `yaml:"keys_dir" env:"KEYS_DIR"`
+	SenderKeyFile  *string  `yaml:"sender_key_file" env:"SENDER_KEY_FILE"`
+	FundingKeyFile *string  `yaml:"funding_key_file" env:"FUNDING_KEY_FILE"`
+	ValidatorAddr  *string  `yaml:"validator_address" env:"VALIDATOR_ADDRESS"`
+	RewardAddress  *string  `yaml:"reward_address" env:"REWARD_ADDRESS"`
+	SenderAddress  *string  `yaml:"sender_address" env:"SENDER_ADDRESS"`
+	BalanceAddress *string  `yaml:"balance_check_address" env:"BALANCE_CHECK_ADDRESS"`
+	FundingAddress *string  `yaml:"funding_address" env:"FUNDING_ADDRESS"`
+	StakerAddress  *string  `yaml:"staker_address" env:"STAKER_ADDRESS"`
+	MinStake       *float64 `yaml:"min_validator_stake" env:"MIN_VALIDATOR_STAKE"`
+	StakeBuffer    *float64 `yaml:"stake_buffer_nim" env:"STAKE_BUFFER_NIM"`
+	MaxFeeLuna     *int     `yaml:"max_fee_luna" env:"MAX_FEE_LUNA"`
+	TxFeeLuna      *int     `yaml:"tx_fee_luna" env:"TX_FEE_LUNA"`
+	JailRelease    *int     `yaml:"jail_release_blocks" env:"JAIL_RELEASE_BLOCKS"`
+	BlocksPerBatch *int     `yaml:"blocks_per_batch" env:"BLOCKS_PER_BATCH"`
+	UnlockDuration *string  `yaml:"unlock_duration" env:"UNLOCK_DURATION"`
+	FeeBumpFactor  *float64 `yaml:"fee_bump_factor" env:"FEE_BUMP_FACTOR"`
+	FeeBumps       *int     `yaml:"fee_bump_max_attempts" env:"FEE_BUMP_MAX_ATTEMPTS"`
+	TopUpEnabled   *bool    `yaml:"topup_enabled" env:"TOPUP_ENABLED"`
+	TopUpMaxNIM    *float64 `yaml:"topup_max_nim_per_period" env:"TOPUP_MAX_NIM_PER_PERIOD"`
+	TopUpPeriod    *string  `yaml:"topup_period" env:"TOPUP_PERIOD"`
+	Activation     *bool    `yaml:"activation_enabled" env:"ACTIVATION_ENABLED"`
+	DryRun         *bool    `yaml:"dry_run" env:"DRY_RUN"`
+	AutoReactivate *bool    `yaml:"auto_reactivate_retired" env:"AUTO_REACTIVATE_RETIRED"`
+	JailThreshold  *int     `yaml:"jail_escalation_threshold" env:"JAIL_ESCALATION_THRESHOLD"`
+	JailWindow     *string  `yaml:"jail_escalation_window" env:"JAIL_ESCALATION_WINDOW"`
+	ConfirmTimeout *string  `yaml:"confirmation_timeout" env:"CONFIRMATION_TIMEOUT"`
+	PollInterval   *string  `yaml:"poll_interval" env:"POLL_INTERVAL"`
+	FundingPoll    *string  `yaml:"funding_poll_interval" env:"FUNDING_POLL_INTERVAL"`
+	NimiqPoll      *string  `yaml:"nimiq_poll_interval" env:"NIMIQ_POLL_INTERVAL"`
+	NimiqFundPoll  *string  `yaml:"nimiq_funding_poll_interval" env:"NIMIQ_FUNDING_POLL_INTERVAL"`
+	ConfirmPoll    *string  `yaml:"confirmation_poll_interval" env:"CONFIRMATION_POLL_INTERVAL"`
+	ConfirmDepth   *int     `yaml:"confirmation_depth" env:"CONFIRMATION_DEPTH"`
+	StakersRefresh *string  `yaml:"stakers_refresh_interval" env:"STAKERS_REFRESH_INTERVAL"`
+	ChainAdvance   *string  `yaml:"chain_advance_check_interval" env:"CHAIN_ADVANCE_CHECK_INTERVAL"`
+	BalanceEMA     *float64 `yaml:"balance_ema_alpha" env:"BALANCE_EMA_ALPHA"`
+	SigningMode    *string  `yaml:"tx_signing_mode" env:"TX_SIGNING_MODE"`
+	StateFile      *string  `yaml:"validator_state_file" env:"VALIDATOR_STATE_FILE"`
+	RecreateDel    *bool    `yaml:"allow_recreate_deleted" env:"ALLOW_RECREATE_DELETED"`
+	MaxIterations  *int     `yaml:"max_iterations" env:"MAX_ITERATIONS"`
+	MaxRuntime     *string  `yaml:"max_runtime" env:"MAX_RUNTIME"`
+	ReadyActive    *bool    `yaml:"ready_requires_active" env:"READY_REQUIRES_ACTIVE"`
+	SummaryLog     *bool    `yaml:"summary_log" env:"SUMMARY_LOG"`
+	StartupLimit   *string  `yaml:"startup_deadline" env:"STARTUP_DEADLINE"`
+	NoConsensus    *string  `yaml:"on_no_consensus" env:"ON_NO_CONSENSUS"`
+	StableReadings *int     `yaml:"consensus_stable_readings" env:"CONSENSUS_STABLE_READINGS"`
+	ReadingPeriod  *string  `yaml:"consensus_reading_interval" env:"CONSENSUS_READING_INTERVAL"`
+	LeaseFile      *string  `yaml:"leader_lease_file" env:"LEADER_LEASE_FILE"`
+	LeaseTTL       *string  `yaml:"leader_lease_ttl" env:"LEADER_LEASE_TTL"`
+	MetricsRead    *string  `yaml:"metrics_read_timeout" env:"METRICS_READ_TIMEOUT"`
+	MetricsWrite   *string  `yaml:"metrics_write_timeout" env:"METRICS_WRITE_TIMEOUT"`
+	MetricsIdle    *string  `yaml:"metrics_idle_timeout" env:"METRICS_IDLE_TIMEOUT"`
+}
+
+// applyConnectionString splits NIMIQ_CONNECTION, e.g. "testnet+http://node:8648", into
+// NIMIQ_NETWORK and NIMIQ_NODE_URL. Either variable set on its own overrides its part.
+func applyConnectionString() error {
+	connection := os.Getenv("NIMIQ_CONNECTION")
+	if connection == "" {
+		return nil
+	}
+	connectionNetwork, nodeURL, found := strings.Cut(connection, "+")
+	if !found || nodeURL == "" {
+		return fmt.Errorf("%q must look like <network>+<node url>", connection)
+	}
+	if !knownNetworks[connectionNetwork] {
+		return fmt.Errorf("unknown network %q, expected mainnet or testnet", connectionNetwork)
+	}
+	for key, value := range map[string]string{"NIMIQ_NETWORK": connectionNetwork, "NIMIQ_NODE_URL": nodeURL} {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConfigFile loads a YAML config file and exports its values as environment variables.
+// Variables already present in the environment win, so env vars override the file.
+func applyConfigFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var config fileConfig
+	decoder := yaml.NewDecoder(strings.NewReader(string(content)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	value := reflect.ValueOf(config)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.IsNil() {
+			continue
+		}
+		key := value.Type().Field(i).Tag.Get("env")
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, fmt.Sprint(field.Elem().Interface())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+)
+
+// waitForConfirmation polls the node until the transaction's block is CONFIRMATION_DEPTH blocks deep
+// (the block itself counting as the first), the timeout expires or ctx is canceled
+func waitForConfirmation(ctx context.Context, client NimiqRPC, txHash string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		tx, err := client.GetTransactionByHash(txHash)
+		if err != nil {
+			log.Printf("Transaction %s not found yet: %v", txHash, err)
+		} else if tx.BlockNumber > 0 {
+			if confirmationDepth <= 1 {
+				log.Printf("Transaction %s confirmed in block %d.", txHash, tx.BlockNumber)
+				return true
+			}
+			head, err := client.GetCurrentBlockNumber()
+			if err != nil {
+				log.Printf("Error fetching current block number: %v", err)
+			} else if depth := head - tx.BlockNumber + 1; depth >= int64(confirmationDepth) {
+				log.Printf("Transaction %s confirmed in block %d, %d blocks deep.", txHash, tx.BlockNumber, depth)
+				return true
+			} else {
+				log.Printf("Transaction %s in block %d, %d of %d confirmations.", txHash, tx.BlockNumber, max(depth, 0), confirmationDepth)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			log.Printf("Stopped waiting for transaction %s: %v", txHash, ctx.Err())
+			return false
+		case <-time.After(confirmationPollInterval):
+		}
+	}
+	return false
+}
+
+// nextBumpedFee increases fee by the configured bump factor, capped at MAX_FEE_LUNA
+func nextBumpedFee(fee int) int {
+	bumped := int(math.Ceil(float64(fee) * feeBumpFactor))
+	if bumped > maxFeeLuna {
+		bumped = maxFeeLuna
+	}
+	return bumped
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestWaitForConfirmationCanceled checks that canceling the context stops waiting long before the timeout
+func TestWaitForConfirmationCanceled(t *testing.T) {
+	confirmationPollInterval = time.Hour
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	started := time.Now()
+	if waitForConfirmation(ctx, &mockNode{}, "unknown-tx-hash", time.Hour) {
+		t.Fatal("unknown transaction reported as confirmed")
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Fatalf("waited %s after cancellation", elapsed)
+	}
+}
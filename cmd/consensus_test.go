@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// flippingNode reports scripted consensus readings, the last one repeating once they are used up
+type flippingNode struct {
+	mockNode
+	readings []bool
+	calls    int
+}
+
+func (n *flippingNode) IsConsensusEstablished() (bool, error) {
+	reading := n.readings[min(n.calls, len(n.readings)-1)]
+	n.calls++
+	return reading, nil
+}
+
+func TestCheckConsensus(t *testing.T) {
+	tests := []struct {
+		name      string
+		required  int
+		readings  []bool
+		want      bool
+		wantCalls int
+	}{
+		{"stable", 3, []bool{true, true, true}, true, 3},
+		{"single reading", 1, []bool{true}, true, 1},
+		{"not established", 3, []bool{false}, false, 1},
+		{"lost on second reading", 3, []bool{true, false, true}, false, 2},
+		{"lost on last reading", 3, []bool{true, true, false}, false, 3},
+		{"flipping", 2, []bool{true, false, true, true}, false, 2},
+		{"more readings required", 5, []bool{true, true, true, true, true}, true, 5},
+	}
+	consensusReadingInterval = time.Millisecond
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			consensusStableReadings = test.required
+			node := &flippingNode{readings: test.readings}
+			if got := checkConsensus(node); got != test.want {
+				t.Errorf("checkConsensus = %t, want %t", got, test.want)
+			}
+			if node.calls != test.wantCalls {
+				t.Errorf("took %d readings, want %d", node.calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+// TestCheckConsensusRecovers checks that a check after a flip passes once the node is stable again
+func TestCheckConsensusRecovers(t *testing.T) {
+	consensusReadingInterval = time.Millisecond
+	consensusStableReadings = 2
+	node := &flippingNode{readings: []bool{true, false, true, true}}
+	if checkConsensus(node) {
+		t.Fatal("first check passed although consensus flipped")
+	}
+	if !checkConsensus(node) {
+		t.Fatal("second check failed although consensus is stable")
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+var (
+	// validatorSeen is set once the validator was observed on chain, so a later not-found means it was deleted
+	validatorSeen atomic.Bool
+	// validatorDeleted is the terminal deleted state, kept until re-enabled by the operator
+	validatorDeleted atomic.Bool
+)
+
+// loadDeletedState restores the deleted state recorded in VALIDATOR_STATE_FILE by an earlier run
+func loadDeletedState(address string) {
+	prometheus.ValidatorDeletedGauge.WithLabelValues(address).Set(0)
+	if validatorStateFile == "" {
+		return
+	}
+	content, err := os.ReadFile(validatorStateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("Error reading validator state file %s: %v", validatorStateFile, err)
+		}
+		return
+	}
+	if !sameAddress(strings.TrimSpace(string(content)), address) {
+		return
+	}
+	if allowRecreateDeleted {
+		log.Printf("Validator %s was deleted earlier, recreating it as ALLOW_RECREATE_DELETED is set.", address)
+		return
+	}
+	log.Printf("Validator %s was deleted earlier. Not recreating it, remove %s or set ALLOW_RECREATE_DELETED=true to re-enable.", address, validatorStateFile)
+	validatorDeleted.Store(true)
+	prometheus.ValidatorDeletedGauge.WithLabelValues(address).Set(1)
+}
+
+// markValidatorSeen records that the validator exists on chain
+func markValidatorSeen() {
+	validatorSeen.Store(true)
+}
+
+// isValidatorDeleted reports whether a not-found validator was deleted and must not be recreated.
+// Only a validator we saw earlier that the node now reports as not found counts as deleted,
+// transport errors never do.
+func isValidatorDeleted(address string, err error) bool {
+	if validatorDeleted.Load() {
+		return true
+	}
+	if allowRecreateDeleted || !validatorSeen.Load() || !errors.Is(err, rpc.ErrValidatorNotFound) {
+		return false
+	}
+
+	log.Printf("Validator %s disappeared after being active, it was deleted. Not recreating it automatically.", address)
+	validatorDeleted.Store(true)
+	prometheus.ValidatorDeletedGauge.WithLabelValues(address).Set(1)
+	if validatorStateFile != "" {
+		if err := os.WriteFile(validatorStateFile, []byte(address+"\n"), 0o600); err != nil {
+			log.Printf("Error persisting deleted state to %s: %v", validatorStateFile, err)
+		}
+	}
+	return true
+}
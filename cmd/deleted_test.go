@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestPeriodicUpdatesSkipsDeleted checks that a validator the operator deleted is never funded
+func TestPeriodicUpdatesSkipsDeleted(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	validatorDeleted.Store(true)
+	defer validatorDeleted.Store(false)
+	network, faucetURL, minStakeNIM = "testnet", "http://faucet.invalid", 1
+	node := &mockNode{consensus: true, address: address}
+	faucetClient = &mockFaucet{node: node, amount: 10 * lunaPerNIM}
+	setLeader(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := periodicUpdates(ctx, node, address, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if node.balance != 0 || len(node.sent) != 0 {
+		t.Errorf("deleted validator funded: balance %d, transactions %v", node.balance, node.sent)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"nimiq-validator-activator/prometheus"
+)
+
+// Mutating actions skipped in dry-run mode, as labeled in nimiq_activator_dryrun_skipped_total
+const (
+	dryRunActivation   = "activation"
+	dryRunReactivation = "reactivation"
+	dryRunFunding      = "funding"
+	dryRunTopUp        = "topup"
+)
+
+// errDryRunSkipped is returned by actions that DRY_RUN kept from running
+var errDryRunSkipped = errors.New("skipped in dry-run mode")
+
+// skipForDryRun reports whether DRY_RUN is enabled. If so it logs what the mutating action would
+// have done and counts it, so dry runs can be audited before enabling real sends.
+func skipForDryRun(action, format string, args ...interface{}) bool {
+	if !dryRun {
+		return false
+	}
+	log.Printf("[DRY-RUN] would %s", fmt.Sprintf(format, args...))
+	prometheus.ActivatorDryRunSkippedCounter.WithLabelValues(action).Inc()
+	return true
+}
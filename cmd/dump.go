@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// collectMetrics runs one read-only monitoring iteration, never sending transactions
+func collectMetrics(client NimiqRPC, address string) {
+	updateEpochNumberGauge(client)
+	updateActiveValidatorCount(client)
+	checkSufficientBalance(client, address)
+	updateStakerMetrics(client, address)
+
+	details, err := client.GetValidatorByAddress(address)
+	if err != nil {
+		log.Println("Error fetching validator details:", err)
+		return
+	}
+	updateValidatorMetrics(address, details)
+	updateParkedGauge(client, address)
+}
+
+// dumpMetrics runs one monitoring iteration and prints all metrics in Prometheus text format
+func dumpMetrics(client NimiqRPC) error {
+	address, err := resolveValidatorAddress(client)
+	if err != nil {
+		return fmt.Errorf("error fetching validator address: %w", err)
+	}
+	collectMetrics(client, address)
+
+	families, err := promclient.DefaultGatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("error gathering metrics: %w", err)
+	}
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(os.Stdout, family); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"nimiq-validator-activator/faucet"
+	"nimiq-validator-activator/prometheus"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errFaucetThrottled is returned when a funding request comes before the faucet throttle allows it
+var errFaucetThrottled = errors.New("faucet requests throttled")
+
+var (
+	// faucetMu guards the faucet throttle so the poll loop and manual triggers share it
+	faucetMu sync.Mutex
+	// faucetNextAllowed is the earliest time of the next faucet request
+	faucetNextAllowed time.Time
+	// faucetBackoff grows after failed requests and resets after a successful one
+	faucetBackoff time.Duration
+	// fundTarget is the resolved validator address manual funding requests are sent for
+	fundTarget atomic.Value
+	// faucetUnreachableCount counts consecutive requests that got no answer from the faucet
+	faucetUnreachableCount int
+)
+
+const (
+	// maxFaucetBackoff caps the wait after repeated faucet failures
+	maxFaucetBackoff = 5 * time.Minute
+	// faucetUnreachableThreshold is the number of unanswered requests in a row after which the faucet counts as unreachable
+	faucetUnreachableThreshold = 3
+)
+
+// errFundingUnconfirmed is returned when the faucet's funding transaction did not confirm within FAUCET_CONFIRM_TIMEOUT
+var errFundingUnconfirmed = errors.New("funding transaction not confirmed")
+
+// errFaucetRejected is returned when the faucet answered but refused to fund the address
+var errFaucetRejected = errors.New("faucet rejected the funding request")
+
+// Reasons a faucet request failed, as labeled in nimiq_faucet_failures_total
+const (
+	faucetUnreachable = "unreachable" // DNS, connection or timeout errors, no answer at all
+	faucetAuthFailed  = "auth_failed" // The anti-abuse token could not be fetched, the faucet was not contacted
+	faucetHTTPError   = "http_error"  // The faucet answered with a server error
+	faucetRejected    = "rejected"    // The faucet refused the request, e.g. an invalid address or rate limit
+)
+
+// classifyFaucetFailure tells a faucet that cannot be reached apart from one that answered with an error
+func classifyFaucetFailure(result faucet.FundResult, err error) string {
+	switch {
+	case errors.Is(err, faucet.ErrTokenFetch):
+		return faucetAuthFailed
+	case result.StatusCode == 0:
+		return faucetUnreachable
+	case result.StatusCode >= http.StatusInternalServerError:
+		return faucetHTTPError
+	default:
+		return faucetRejected
+	}
+}
+
+// recordFaucetFailure meters a failed request and flags the faucet as unreachable once it stopped
+// answering several times in a row. Any answer from the faucet clears the flag, a failed token
+// fetch leaves it as it is because the faucet itself was not contacted.
+func recordFaucetFailure(reason string) {
+	prometheus.FaucetFailuresCounter.WithLabelValues(reason).Inc()
+	if reason == faucetAuthFailed {
+		return
+	}
+	if reason != faucetUnreachable {
+		faucetUnreachableCount = 0
+		prometheus.FaucetUnreachableGauge.Set(0)
+		return
+	}
+	faucetUnreachableCount++
+	if faucetUnreachableCount == faucetUnreachableThreshold {
+		log.Printf("Faucet unreachable in %d requests in a row, backing off up to %s between attempts.", faucetUnreachableCount, maxFaucetBackoff)
+	}
+	if faucetUnreachableCount >= faucetUnreachableThreshold {
+		prometheus.FaucetUnreachableGauge.Set(1)
+	}
+}
+
+// requestFunding asks the faucet for funds, honoring FAUCET_MIN_INTERVAL between requests
+// and backing off exponentially while the faucet keeps failing
+func requestFunding(address string) (faucet.FundResult, error) {
+	faucetMu.Lock()
+	defer faucetMu.Unlock()
+
+	if wait := time.Until(faucetNextAllowed); wait > 0 {
+		return faucet.FundResult{}, fmt.Errorf("%w, next request allowed in %s", errFaucetThrottled, wait.Round(time.Second))
+	}
+
+	started := time.Now()
+	result, err := faucetClient.Fund(address)
+	if err == nil && !result.Success {
+		err = errFaucetRejected
+	}
+	if err != nil {
+		reason := classifyFaucetFailure(result, err)
+		recordFaucetFailure(reason)
+		faucetBackoff = min(max(2*faucetBackoff, faucetMinInterval, time.Second), maxFaucetBackoff)
+		faucetNextAllowed = started.Add(faucetBackoff)
+		return result, fmt.Errorf("faucet %s: %w", strings.ReplaceAll(reason, "_", " "), err)
+	}
+	faucetUnreachableCount = 0
+	prometheus.FaucetUnreachableGauge.Set(0)
+	faucetBackoff = 0
+	faucetNextAllowed = started.Add(faucetMinInterval)
+	return result, nil
+}
+
+// requestAndConfirmFunding sends one faucet request for address, the single path for the funding loop
+// and POST /fund alike, so leadership, dry-run, throttling and confirmation apply to both
+func requestAndConfirmFunding(ctx context.Context, client NimiqRPC, address string) (faucet.FundResult, error) {
+	if !isLeader() {
+		return faucet.FundResult{}, errNotLeader
+	}
+	if skipForDryRun(dryRunFunding, "request faucet funding for %s", address) {
+		return faucet.FundResult{}, errDryRunSkipped
+	}
+	result, err := requestFunding(address)
+	if err != nil {
+		return result, err
+	}
+	if result.Message != "" {
+		log.Printf("Faucet response: %s", result.Message)
+	}
+
+	// Wait for the funds to arrive instead of re-checking a balance that cannot have changed yet
+	if result.TxHash != "" && faucetConfirmTimeout > 0 {
+		if !waitForConfirmation(ctx, client, result.TxHash, faucetConfirmTimeout) {
+			return result, fmt.Errorf("%w: %s within %s", errFundingUnconfirmed, result.TxHash, faucetConfirmTimeout)
+		}
+		prometheus.FaucetFundingConfirmedCounter.Inc()
+	}
+	return result, nil
+}
+
+// faucetInCooldown reports whether the faucet throttle currently holds back funding requests
+func faucetInCooldown() bool {
+	faucetMu.Lock()
+	defer faucetMu.Unlock()
+	return time.Now().Before(faucetNextAllowed)
+}
+
+// fundResponse is the JSON answer of the /fund endpoint
+type fundResponse struct {
+	Address    string `json:"address"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Message    string `json:"message,omitempty"`
+	TxHash     string `json:"tx_hash,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"` // The request was skipped because DRY_RUN is enabled
+	Error      string `json:"error,omitempty"`
+}
+
+// fundHandler triggers one funding request for the validator address on POST /fund, going through
+// the same leadership, dry-run, throttle and confirmation checks as the funding loop
+func fundHandler(client NimiqRPC) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		address, _ := fundTarget.Load().(string)
+		if address == "" {
+			http.Error(w, "validator address not resolved yet", http.StatusServiceUnavailable)
+			return
+		}
+
+		response := fundResponse{Address: address}
+		status := http.StatusOK
+		result, err := requestAndConfirmFunding(r.Context(), client, address)
+		response.StatusCode, response.Message, response.TxHash = result.StatusCode, result.Message, result.TxHash
+		switch {
+		case errors.Is(err, errNotLeader):
+			status = http.StatusConflict
+		case errors.Is(err, errDryRunSkipped):
+			response.DryRun = true
+		case errors.Is(err, errFaucetThrottled):
+			status = http.StatusTooManyRequests
+		case errors.Is(err, errFundingUnconfirmed):
+			status = http.StatusGatewayTimeout
+		case err != nil:
+			status = http.StatusBadGateway
+		default:
+			response.Success = true
+		}
+		if err != nil {
+			response.Error = err.Error()
+		}
+		log.Printf("Manual funding request for %s: success=%t %s", address, response.Success, response.Error)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			log.Println("Error writing fund response:", err)
+		}
+	}
+}
+
+// requireMetricsToken protects a handler with METRICS_TOKEN sent as a bearer token. Without
+// a token configured read-only endpoints stay open, while /fund is refused entirely.
+func requireMetricsToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if metricsToken == "" {
+			if r.URL.Path == "/fund" {
+				http.Error(w, "set METRICS_TOKEN to enable /fund", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+metricsToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"nimiq-validator-activator/faucet"
+	"testing"
+	"time"
+)
+
+// TestFundHandlerDryRun checks that POST /fund never reaches the faucet while DRY_RUN is enabled
+func TestFundHandlerDryRun(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	faucetClient = faucet.NewHTTPFaucet(server.URL)
+	dryRun = true
+	defer func() { dryRun = false }()
+	setLeader(true)
+	fundTarget.Store("NQ07 0000 0000 0000 0000 0000 0000 0000 0000")
+
+	recorder := httptest.NewRecorder()
+	fundHandler(&mockNode{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/fund", nil))
+
+	var response fundResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Errorf("faucet got %d requests in dry-run mode, want 0", requests)
+	}
+	if recorder.Code != http.StatusOK || !response.DryRun || response.Success {
+		t.Errorf("got status %d and %+v, want a skipped dry-run response", recorder.Code, response)
+	}
+}
+
+// TestRequestFundingBackoff checks that a failed faucet request holds back the next one
+func TestRequestFundingBackoff(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	faucetClient = faucet.NewHTTPFaucet(server.URL)
+	faucetMinInterval = 0
+	faucetNextAllowed, faucetBackoff = time.Time{}, 0
+
+	if _, err := requestFunding("NQ07"); err == nil || errors.Is(err, errFaucetThrottled) {
+		t.Fatalf("first request error = %v, want a faucet failure", err)
+	}
+	if _, err := requestFunding("NQ07"); !errors.Is(err, errFaucetThrottled) {
+		t.Fatalf("second request error = %v, want throttled", err)
+	}
+	if requests != 1 {
+		t.Errorf("faucet got %d requests, want 1", requests)
+	}
+}
+
+func TestClassifyFaucetFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		result faucet.FundResult
+		err    error
+		want   string
+	}{
+		{"no answer", faucet.FundResult{}, errors.New("connection refused"), faucetUnreachable},
+		{"token fetch failed", faucet.FundResult{}, fmt.Errorf("%w: connection refused", faucet.ErrTokenFetch), faucetAuthFailed},
+		{"server error", faucet.FundResult{StatusCode: http.StatusBadGateway}, errors.New("bad gateway"), faucetHTTPError},
+		{"rejected", faucet.FundResult{StatusCode: http.StatusOK}, errFaucetRejected, faucetRejected},
+	}
+	for _, test := range tests {
+		if got := classifyFaucetFailure(test.result, test.err); got != test.want {
+			t.Errorf("%s: classifyFaucetFailure = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
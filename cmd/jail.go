@@ -0,0 +1,72 @@
+package main
+
+import (
+	"log"
+	"nimiq-validator-activator/prometheus"
+	"sync"
+	"time"
+)
+
+var (
+	jailMu sync.Mutex
+	// Jail events seen within the escalation window and the last jail we recorded
+	jailEvents     []time.Time
+	lastJailedFrom int
+	jailEscalated  bool
+	// When the current jailing was first detected, zero once the validator is out of jail
+	jailDetectedAt time.Time
+)
+
+// recordJailEvent tracks a new jailing and escalates once too many happen within the window.
+// Once escalated, automatic reactivation of a retired validator stays disabled until the activator
+// is restarted. Jailed validators are never reactivated automatically, so escalation does not change
+// how a jailing itself is handled. It reports whether the jailing was not recorded before.
+func recordJailEvent(address string, jailedFrom int) bool {
+	jailMu.Lock()
+	defer jailMu.Unlock()
+	if jailedFrom == lastJailedFrom {
+		return false // Same jail we already recorded
+	}
+	lastJailedFrom = jailedFrom
+
+	now := time.Now()
+	if jailDetectedAt.IsZero() {
+		jailDetectedAt = now
+	}
+	recent := jailEvents[:0]
+	for _, event := range jailEvents {
+		if now.Sub(event) <= jailEscalationWindow {
+			recent = append(recent, event)
+		}
+	}
+	jailEvents = append(recent, now)
+	log.Printf("Validator jailed from block %d (%d jail events within %s).", jailedFrom, len(jailEvents), jailEscalationWindow)
+
+	if jailEscalationThreshold > 0 && len(jailEvents) >= jailEscalationThreshold && !jailEscalated {
+		jailEscalated = true
+		prometheus.ValidatorJailEscalationGauge.WithLabelValues(address).Set(1)
+		log.Printf("Validator was jailed %d times within %s. Automatic reactivation after retirement disabled, manual intervention required.", len(jailEvents), jailEscalationWindow)
+	}
+	return true
+}
+
+// isJailEscalated reports whether repeated jailing disabled automatic reactivation of a retired validator
+func isJailEscalated() bool {
+	jailMu.Lock()
+	defer jailMu.Unlock()
+	return jailEscalated
+}
+
+// recordJailRecovery observes the time from jail detection until the validator is seen in good
+// standing again, doing nothing when it was not jailed before
+func recordJailRecovery(address string) {
+	jailMu.Lock()
+	defer jailMu.Unlock()
+	if jailDetectedAt.IsZero() {
+		return
+	}
+	recovery := time.Since(jailDetectedAt)
+	jailDetectedAt = time.Time{}
+	prometheus.ValidatorJailRecoverySeconds.WithLabelValues(address).Observe(recovery.Seconds())
+	log.Printf("Validator recovered from jail after %s.", recovery.Round(time.Second))
+}
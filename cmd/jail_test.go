@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"testing"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// recoveryCount reads how many jail recoveries were observed for address
+func recoveryCount(t *testing.T, address string) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := prometheus.ValidatorJailRecoverySeconds.WithLabelValues(address).(promclient.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.Histogram.GetSampleCount()
+}
+
+// TestJailRecoveryObservedWhenJailEnds checks that the recovery time is observed once the validator leaves jail
+func TestJailRecoveryObservedWhenJailEnds(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	ctx := context.Background()
+	jailReleaseBlocks = 8000
+	jailEscalationThreshold = 0
+	defer validatorSeen.Store(false)
+	deposit := int64(1)
+	node := &mockNode{consensus: true, address: address, blockNumber: 100000}
+	node.validator = &rpc.ValidatorDetails{Address: address, Balance: &deposit}
+	before := recoveryCount(t, address)
+
+	jailedFrom := int(node.blockNumber)
+	node.validator.JailedFrom = &jailedFrom
+	checkAndHandleValidatorStatus(ctx, node, address)
+	checkAndHandleValidatorStatus(ctx, node, address)
+	if got := recoveryCount(t, address) - before; got != 0 {
+		t.Fatalf("%d recoveries observed while jailed, want 0", got)
+	}
+
+	node.validator.JailedFrom = nil
+	checkAndHandleValidatorStatus(ctx, node, address)
+	checkAndHandleValidatorStatus(ctx, node, address)
+	if got := recoveryCount(t, address) - before; got != 1 {
+		t.Fatalf("%d recoveries observed after the jail ended, want 1", got)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeKeyFile writes content to an owner-only key file in a temporary directory
+func writeKeyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.txt")
+	writeKeyFileAt(t, path, content)
+	return path
+}
+
+// writeKeyFileAt writes content to an owner-only key file at path
+func writeKeyFileAt(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const multiKeyFile = `Address: NQ07 0000 0000 0000 0000 0000 0000 0000 0000
+Public Key: aa
+Private Key: 1111
+
+Address: NQ28 1111 1111 1111 1111 1111 1111 1111 1111
+Public Key: bb
+Private Key: 2222
+`
+
+func TestGetPrivateKeyAt(t *testing.T) {
+	path := writeKeyFile(t, multiKeyFile)
+	tests := []struct {
+		index   int
+		want    string
+		wantErr bool
+	}{
+		{0, "1111", false},
+		{1, "2222", false},
+		{2, "", true},
+	}
+	for _, test := range tests {
+		got, err := getPrivateKeyAt(path, test.index)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("getPrivateKeyAt(%d) = %q, %v, want %q", test.index, got, err, test.want)
+		}
+	}
+}
+
+func TestGetPrivateKeyForAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		address string
+		want    string
+		wantErr bool
+	}{
+		{"first section", multiKeyFile, "NQ07 0000 0000 0000 0000 0000 0000 0000 0000", "1111", false},
+		{"second section", multiKeyFile, "nq28 1111 1111 1111 1111 1111 1111 1111 1111", "2222", false},
+		{"unknown address", multiKeyFile, "NQ45 2222 2222 2222 2222 2222 2222 2222 2222", "", true},
+		{"no sections", "Private Key: 3333\nPrivate Key: 4444\n", "NQ45 2222 2222 2222 2222 2222 2222 2222 2222", "3333", false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getPrivateKeyForAddress(writeKeyFile(t, test.content), test.address)
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("getPrivateKeyForAddress = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestGetVoteKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"nimiq-bls file", "# Public Key:\n\nabcd\n\n# Proof Of Knowledge:\n\n1234\n\n# Secret Key:\n\n00ff\n", "00ff", false},
+		{"without proof of knowledge", "# Public Key:\nabcd\n\n# Secret Key:\n00ff\n", "00ff", false},
+		{"malformed proof of knowledge", "# Secret Key:\n00ff\n\n# Proof Of Knowledge:\nnot-hex\n", "", true},
+		{"secret key not hex", "# Secret Key:\nsecret\n", "", true},
+		{"missing secret key", "# Public Key:\nabcd\n", "", true},
+		{"empty secret key section", "# Secret Key:\n\n# Proof Of Knowledge:\n1234\n", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getVoteKey(writeKeyFile(t, test.content))
+			if (err != nil) != test.wantErr || got != test.want {
+				t.Errorf("getVoteKey = %q, %v, want %q", got, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"nimiq-validator-activator/prometheus"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// errNotLeader is returned when a follower replica is asked to perform a mutating action
+var errNotLeader = errors.New("not the leader, leaving mutating actions to the leader replica")
+
+// leader is true while this replica holds the lease, always true without a lease file
+var leader atomic.Bool
+
+// leaseHolderID identifies this replica in the lease file
+var leaseHolderID = func() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}()
+
+// isLeader reports whether this replica may import keys and send transactions
+func isLeader() bool {
+	return leader.Load()
+}
+
+// startLeaderElection acquires and renews the lease in the background until ctx is canceled.
+// Without LEADER_LEASE_FILE every replica acts as leader.
+func startLeaderElection(ctx context.Context) {
+	if leaderLeaseFile == "" {
+		setLeader(true)
+		return
+	}
+
+	log.Printf("Leader election enabled using lease file %s (TTL %s) as %s", leaderLeaseFile, leaderLeaseTTL, leaseHolderID)
+	setLeader(tryAcquireLease())
+	go func() {
+		ticker := time.NewTicker(leaderLeaseTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				setLeader(tryAcquireLease())
+			}
+		}
+	}()
+}
+
+func setLeader(isLeader bool) {
+	if leader.Swap(isLeader) != isLeader {
+		if isLeader {
+			log.Printf("Acquired leadership.")
+		} else {
+			log.Printf("Lost leadership, monitoring only.")
+		}
+	}
+	value := float64(0)
+	if isLeader {
+		value = 1
+	}
+	prometheus.ActivatorIsLeaderGauge.Set(value)
+}
+
+// tryAcquireLease takes or renews the lease when it is free, expired or already ours. The lease
+// is read and written while holding the lease lock, so two replicas can never both take it.
+func tryAcquireLease() bool {
+	unlock, err := lockLeaseFile()
+	if err != nil {
+		log.Println("Error locking leader lease:", err)
+		return false
+	}
+	defer unlock()
+
+	holder, expires, err := readLease()
+	if err != nil && !os.IsNotExist(err) {
+		log.Println("Error reading leader lease:", err)
+	}
+	if err == nil && holder != leaseHolderID && time.Now().Before(expires) {
+		return false // Held by another live replica
+	}
+
+	if err := writeLease(time.Now().Add(leaderLeaseTTL)); err != nil {
+		log.Println("Error writing leader lease:", err)
+		return false
+	}
+	return true
+}
+
+// confirmLeadership renews the lease right before a transaction is sent, so a replica that lost
+// the lease since the last renewal never broadcasts
+func confirmLeadership() error {
+	if leaderLeaseFile != "" {
+		setLeader(tryAcquireLease())
+	}
+	if !isLeader() {
+		return errNotLeader
+	}
+	return nil
+}
+
+// readLease parses the lease file, formatted as "<holder> <expiry unix nanoseconds>"
+func readLease() (string, time.Time, error) {
+	content, err := os.ReadFile(leaderLeaseFile)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		return "", time.Time{}, fmt.Errorf("malformed lease file")
+	}
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("malformed lease expiry: %w", err)
+	}
+	return fields[0], time.Unix(0, expires), nil
+}
+
+// writeLease atomically replaces the lease file with this replica as holder
+func writeLease(expires time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(leaderLeaseFile), ".lease-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := fmt.Fprintf(tmp, "%s %d\n", leaseHolderID, expires.UnixNano()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), leaderLeaseFile)
+}
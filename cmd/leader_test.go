@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTryAcquireLease(t *testing.T) {
+	leaderLeaseFile = filepath.Join(t.TempDir(), "leader.lease")
+	leaderLeaseTTL = time.Hour
+	defer func() { leaderLeaseFile = "" }()
+	self := leaseHolderID
+	defer func() { leaseHolderID = self }()
+
+	if !tryAcquireLease() {
+		t.Fatal("free lease not acquired")
+	}
+	if !tryAcquireLease() {
+		t.Fatal("own lease not renewed")
+	}
+	leaseHolderID = "other-replica"
+	if tryAcquireLease() {
+		t.Fatal("lease held by a live replica was taken over")
+	}
+	if err := confirmLeadership(); !errors.Is(err, errNotLeader) {
+		t.Fatalf("confirmLeadership = %v, want errNotLeader", err)
+	}
+
+	leaderLeaseTTL = -time.Second
+	leaseHolderID = self
+	if !tryAcquireLease() { // Writes an already expired lease
+		t.Fatal("lease not renewed")
+	}
+	leaseHolderID = "other-replica"
+	if !tryAcquireLease() {
+		t.Fatal("expired lease not taken over")
+	}
+}
+
+// TestLockLeaseFileExcludes checks that a second lock holder waits until the first one releases the lock
+func TestLockLeaseFileExcludes(t *testing.T) {
+	leaderLeaseFile = filepath.Join(t.TempDir(), "leader.lease")
+	defer func() { leaderLeaseFile = "" }()
+
+	unlock, err := lockLeaseFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan struct{})
+	go func() {
+		second, err := lockLeaseFile()
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		second()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock not acquired after release")
+	}
+}
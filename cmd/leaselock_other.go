@@ -0,0 +1,10 @@
+//go:build !unix
+
+package main
+
+import "errors"
+
+// lockLeaseFile is unsupported without flock, so replicas never elect themselves leader
+func lockLeaseFile() (func(), error) {
+	return nil, errors.New("leader election requires a Unix system")
+}
@@ -1,56 +1,68 @@
 package main
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
-	"net/http"
-	"net/url"
+	"math"
+	"nimiq-validator-activator/logging"
 	"nimiq-validator-activator/prometheus"
 	"nimiq-validator-activator/rpc"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
-
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-var (
-	faucetURL    string
-	network      string
-	nimiqNodeUrl string
-	servingPort  = getServingPort()
+const (
+	appVersion = "1.0.0"
+	lunaPerNIM = 100000.0
 )
 
 func init() {
 	// Set log flags to include date and time in log messages
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+}
 
-	// Fetching faucet URL from environment variable with a default value
-	nimiqNodeUrl = os.Getenv("NIMIQ_NODE_URL")
-	if nimiqNodeUrl == "" {
-		nimiqNodeUrl = "http://node:8648"
-	}
-
-	// Fetching faucet URL from environment variable with a default value
-	faucetURL = os.Getenv("FAUCET_URL")
-	if faucetURL == "" {
-		faucetURL = "https://faucet.pos.nimiq-testnet.com/tapit"
-	}
-
-	// Fetching network type from environment variable with a default value
-	network = os.Getenv("NIMIQ_NETWORK")
-	if network == "" {
-		network = "testnet" // Assuming 'testnet' as default, adjust as needed
-	}
-
-	log.Printf("Nimiq Node URL: %s", nimiqNodeUrl)
-	log.Printf("Faucet URL: %s", faucetURL)
-	log.Printf("Network: %s", network)
+// NimiqRPC is the subset of the Nimiq node RPC used by the activator
+type NimiqRPC interface {
+	Ping() error
+	IsConsensusEstablished() (bool, error)
+	GetEpochNumber() (int, error)
+	GetAddress() (string, error)
+	GetElectionBlockOf(epoch int) (int64, error)
+	GetInherentsByBlockNumber(blockNumber int64) ([]rpc.Inherent, error)
+	GetBlockByNumber(blockNumber int64) (*rpc.Block, error)
+	ListAccounts() ([]string, error)
+	GetCurrentBlockNumber() (int64, error)
+	GetAccountBalanceByAddress(address string) (int64, error)
+	GetAccountBalances(addresses []string) (map[string]int64, error)
+	GetValidatorByAddress(address string) (*rpc.ValidatorDetails, error)
+	GetTotalStakeByValidatorAddress(address string) (int64, error)
+	GetParkedValidators() ([]string, error)
+	GetActiveValidators() ([]rpc.ValidatorDetails, error)
+	GetStakerByAddress(address string) (*rpc.StakerDetails, error)
+	GetTransactionByHash(hash string) (*rpc.Transaction, error)
+	ImportRawKey(privateKey, passphrase string) (string, error)
+	UnlockAccount(address, passphrase string, duration int) error
+	SendNewValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error)
+	SendReactivateValidatorTransaction(senderAddress, validatorAddress, signingSecretKey string, feeInLuna int, validityStartHeight string) (string, error)
+	SendBasicTransaction(wallet, recipient string, value int64, feeInLuna int, validityStartHeight string) (string, error)
+	SendRawTransaction(rawTx string) (string, error)
+	CreateRawValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error)
+	SignTransaction(rawTx, signerAddress string) (string, error)
 }
 
 func getServingPort() string {
-	servingPortStr := os.Getenv("PROMETHEUS_PORT")
+	servingPortStr := getEnv("PROMETHEUS_PORT", "8000")
 	if servingPortStr == "" {
 		return ":8000" // Default port if not set
 	}
@@ -60,216 +72,694 @@ func getServingPort() string {
 	return ":8000" // Default to ":8000" if conversion fails
 }
 
-func checkConsensus(client *rpc.Client) bool {
-	const maxAttempts = 3
-	successfulChecks := 0
+// exportConfigMetrics exposes the effective numeric configuration as gauges
+func exportConfigMetrics() {
+	settings := map[string]float64{
+		"min_stake_nim":                        minStakeNIM,
+		"tx_fee_luna":                          float64(txFeeLuna),
+		"jail_release_blocks":                  float64(jailReleaseBlocks),
+		"blocks_per_batch":                     float64(blocksPerBatch),
+		"poll_interval_seconds":                pollInterval.Seconds(),
+		"funding_poll_interval_seconds":        fundingPollInterval.Seconds(),
+		"stake_buffer_nim":                     stakeBufferNIM,
+		"topup_max_nim_per_period":             topUpMaxNIMPerPeriod,
+		"topup_period_seconds":                 topUpPeriod.Seconds(),
+		"unlock_duration_seconds":              float64(unlockDurationSeconds),
+		"chain_advance_check_interval_seconds": chainAdvanceCheckInterval.Seconds(),
+		"confirmation_timeout_seconds":         confirmationTimeout.Seconds(),
+		"confirmation_depth":                   float64(confirmationDepth),
+		"fee_bump_factor":                      feeBumpFactor,
+		"fee_bump_max_attempts":                float64(feeBumpMaxAttempts),
+		"max_fee_luna":                         float64(maxFeeLuna),
+		"stakers_refresh_interval_seconds":     stakersRefreshInterval.Seconds(),
+		"rpc_max_inflight":                     float64(rpcMaxInFlight),
+		"balance_ema_alpha":                    balanceEMAAlpha,
+		"leader_lease_ttl_seconds":             leaderLeaseTTL.Seconds(),
+		"jail_escalation_threshold":            float64(jailEscalationThreshold),
+		"jail_escalation_window_seconds":       jailEscalationWindow.Seconds(),
+		"max_iterations":                       float64(maxIterations),
+		"max_runtime_seconds":                  maxRuntime.Seconds(),
+		"consensus_stable_readings":            float64(consensusStableReadings),
+	}
+	for name, value := range settings {
+		prometheus.ActivatorConfigGauge.WithLabelValues(name).Set(value)
+	}
+}
 
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
+// checkConsensus reports whether the node established consensus in CONSENSUS_STABLE_READINGS consecutive
+// readings taken CONSENSUS_READING_INTERVAL apart. Any failed or negative reading ends the check.
+func checkConsensus(client NimiqRPC) bool {
+	for reading := 1; reading <= consensusStableReadings; reading++ {
 		consensus, err := client.IsConsensusEstablished()
 		if err != nil {
-			log.Printf("Attempt %d: Error checking consensus: %v\n", attempt, err)
+			log.Printf("Reading %d: Error checking consensus: %v\n", reading, err)
 			log.Println("Waiting 60 seconds before retrying...")
-			time.Sleep(60 * time.Second) // Sleep for 60 seconds
-			return false                 // Immediately return on error
+			time.Sleep(60 * time.Second)
+			return false
 		}
-
-		if consensus {
-			successfulChecks++
-			if successfulChecks == 1 {
-				log.Printf("Consensus established. Verifying stability...")
-			}
-		} else {
+		if !consensus {
 			log.Printf("Consensus not established. Restarting check...")
-			return false // Exit if consensus is not established at any attempt
+			return false
 		}
 
-		// Sleep only if not on the last attempt and consensus is not yet verified
-		if attempt < maxAttempts {
-			time.Sleep(5 * time.Second)
+		if reading == consensusStableReadings {
+			break
 		}
+		if reading == 1 {
+			log.Printf("Consensus established. Verifying stability...")
+		}
+		time.Sleep(consensusReadingInterval)
 	}
 
-	if successfulChecks == maxAttempts {
-		log.Printf("Consensus stability verified. Proceeding...")
-		return true
-	}
-
-	return false
+	log.Printf("Consensus stability verified. Proceeding...")
+	return true
 }
 
-func updateEpochNumberGauge(client *rpc.Client) {
+// Set once the node reports that an optional RPC method is unavailable
+var (
+	epochRewardUnsupported atomic.Bool
+	epochGaugeUnsupported  atomic.Bool
+	parkedSetUnsupported   atomic.Bool
+	activeSetUnsupported   atomic.Bool
+	stakerUnsupported      atomic.Bool
+	headLagUnsupported     atomic.Bool
+	stakersUnsupported     atomic.Bool
+)
+
+func updateEpochNumberGauge(client NimiqRPC) {
+	if epochGaugeUnsupported.Load() {
+		return
+	}
 	epochNumber, err := client.GetEpochNumber()
 	if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getEpochNumber, disabling epoch gauge:", err)
+			epochGaugeUnsupported.Store(true)
+			prometheus.NimiqEpochNumberGauge.Set(math.NaN())
+			return
+		}
 		log.Println("Error fetching epoch number:", err)
 		return
 	}
 	prometheus.NimiqEpochNumberGauge.Set(float64(epochNumber))
+	currentEpoch.Store(int64(epochNumber))
+}
+
+// updateHeadLagGauge exposes how far the head block timestamp trails the wall clock,
+// which reveals a node that is behind even while it claims consensus
+func updateHeadLagGauge(client NimiqRPC) {
+	if headLagUnsupported.Load() {
+		return
+	}
+	head, err := client.GetCurrentBlockNumber()
+	if err != nil {
+		log.Println("Error fetching current block number:", err)
+		return
+	}
+	block, err := client.GetBlockByNumber(head)
+	if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getBlockByNumber, disabling head lag gauge:", err)
+			headLagUnsupported.Store(true)
+			prometheus.NimiqHeadTimestampLagGauge.Set(math.NaN())
+			return
+		}
+		log.Println("Error fetching head block:", err)
+		return
+	}
+	lag := time.Since(time.UnixMilli(block.Timestamp)).Seconds()
+	prometheus.NimiqHeadTimestampLagGauge.Set(lag)
+}
+
+// keyPath resolves a key file name inside the keys directory
+func keyPath(name string) string {
+	return filepath.Join(keysDir, name)
+}
+
+// keyFiles maps the key file labels used in metrics to their file names
+var keyFiles = map[string]string{
+	"signing": "signing_key.txt",
+	"vote":    "vote_key.txt",
+	"address": "address.txt",
+}
+
+// exportKeyFileMetrics reports whether each key file exists and is readable, so failed secret mounts show up in metrics.
+// It returns true when all key files are readable.
+func exportKeyFileMetrics() bool {
+	prometheus.ActivatorKeysDirInfoGauge.WithLabelValues(keysDir).Set(1)
+	// The activator handles a single validator, so KEYS_DIR is the one directory to validate
+	if info, err := os.Stat(keysDir); err != nil {
+		log.Printf("Keys directory %s not accessible: %v", keysDir, err)
+	} else if !info.IsDir() {
+		log.Printf("Keys directory %s is not a directory, check KEYS_DIR.", keysDir)
+	}
+	allPresent := true
+	for label, name := range keyFiles {
+		present := float64(0)
+		if file, err := os.Open(keyPath(name)); err == nil {
+			file.Close()
+			present = 1
+		} else {
+			log.Printf("Key file %s not readable: %v", keyPath(name), err)
+			allPresent = false
+		}
+		prometheus.ActivatorKeyFilePresentGauge.WithLabelValues(label).Set(present)
+	}
+	return allPresent
+}
+
+// exportCanActMetric reports whether this instance is able to activate the validator or only monitors it
+func exportCanActMetric(keysPresent bool) {
+	canAct := float64(0)
+	if keysPresent && activationEnabled {
+		canAct = 1
+	} else if !activationEnabled {
+		log.Printf("Activation disabled, running in monitor-only mode.")
+	} else {
+		log.Printf("Key files missing, running in monitor-only mode.")
+	}
+	prometheus.ActivatorCanActGauge.Set(canAct)
+	canActStatus.Store(canAct == 1)
+}
+
+// readKeyFile reads a file holding key material, checking that only its owner can access it.
+// KEY_FILE_PERMISSIONS=strict refuses looser files, warn only reports them and off skips the check.
+func readKeyFile(filePath string) ([]byte, error) {
+	if keyFilePermissions != "off" {
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+		insecure := float64(0)
+		if mode := info.Mode().Perm(); mode&0o077 != 0 {
+			insecure = 1
+			if keyFilePermissions == "strict" {
+				prometheus.ActivatorKeyFileInsecureGauge.WithLabelValues(filepath.Base(filePath)).Set(insecure)
+				return nil, fmt.Errorf("key file %s has mode %04o, refusing to load keys accessible by other users (expected 0600 or stricter)", filePath, mode)
+			}
+			log.Printf("Key file %s has mode %04o, keys should only be readable by their owner (0600).", filePath, mode)
+		}
+		prometheus.ActivatorKeyFileInsecureGauge.WithLabelValues(filepath.Base(filePath)).Set(insecure)
+	}
+	return os.ReadFile(filePath)
 }
 
 func getPrivateKey(filePath string) (string, error) {
-	// Read the entire file content, assuming the key is the first line of the file
-	content, err := os.ReadFile(filePath)
+	return getPrivateKeyAt(filePath, 0)
+}
+
+// getPrivateKeyAt returns the index-th (zero based) "Private Key:" entry of a key file
+func getPrivateKeyAt(filePath string, index int) (string, error) {
+	content, err := readKeyFile(filePath)
 	if err != nil {
 		return "", err
 	}
 	lines := strings.Split(string(content), "\n")
+	found := 0
 	for _, line := range lines {
 		if strings.HasPrefix(line, "Private Key:") {
-			return strings.TrimSpace(strings.TrimPrefix(line, "Private Key:")), nil
+			if found == index {
+				return strings.TrimSpace(strings.TrimPrefix(line, "Private Key:")), nil
+			}
+			found++
 		}
 	}
+	if index > 0 {
+		return "", fmt.Errorf("private key #%d not found in file, only %d present", index, found)
+	}
 	return "", fmt.Errorf("private key not found in file")
 }
 
+// getPrivateKeyForAddress returns the private key listed in the "Address:" section matching address.
+// Files without any "Address:" line fall back to the first private key.
+func getPrivateKeyForAddress(filePath, address string) (string, error) {
+	content, err := readKeyFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(content), "\n")
+	sectionAddress := ""
+	hasSections := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Address:") {
+			hasSections = true
+			sectionAddress = strings.TrimSpace(strings.TrimPrefix(line, "Address:"))
+			continue
+		}
+		if strings.HasPrefix(line, "Private Key:") && sameAddress(sectionAddress, address) {
+			return strings.TrimSpace(strings.TrimPrefix(line, "Private Key:")), nil
+		}
+	}
+	if !hasSections {
+		return getPrivateKey(filePath)
+	}
+	return "", fmt.Errorf("private key for address %s not found in file", address)
+}
+
+// sameAddress compares two user-friendly addresses ignoring spacing and case
+func sameAddress(a, b string) bool {
+	normalize := func(address string) string {
+		return strings.ToUpper(strings.ReplaceAll(address, " ", ""))
+	}
+	return a != "" && normalize(a) == normalize(b)
+}
+
+// getVoteKey returns the BLS voting secret key from a key file as written by nimiq-bls. The node
+// derives the voting key's proof of knowledge from the secret key itself, so a proof in the file is
+// only checked for being well-formed, never sent.
 func getVoteKey(filePath string) (string, error) {
-	content, err := os.ReadFile(filePath)
+	content, err := readKeyFile(filePath)
 	if err != nil {
 		return "", err
 	}
 	lines := strings.Split(string(content), "\n")
+	secretKey := keySection(lines, "secret key:")
+	if secretKey == "" {
+		return "", fmt.Errorf("vote key not found in file")
+	}
+	if !isHex(secretKey) {
+		return "", fmt.Errorf("vote secret key is not hex encoded, expected a key file generated by nimiq-bls")
+	}
+	if proof := keySection(lines, "proof of knowledge:"); proof == "" {
+		logging.Debugf("Vote key file has no proof of knowledge, the node derives it from the secret key.")
+	} else if !isHex(proof) {
+		return "", fmt.Errorf("vote key proof of knowledge is malformed, regenerate the vote key with nimiq-bls")
+	}
+	return secretKey, nil
+}
+
+// keySection returns the first value line below a header like "# Secret Key:", matched case-insensitively,
+// or "" when the header is missing or directly followed by the next header
+func keySection(lines []string, header string) string {
 	for i, line := range lines {
-		if strings.Contains(line, "Secret Key:") && i+2 < len(lines) {
-			// Assuming the secret key is two lines down from the "Secret Key:" line
-			return strings.TrimSpace(lines[i+2]), nil
+		if !strings.Contains(strings.ToLower(line), header) {
+			continue
+		}
+		for _, value := range lines[i+1:] {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if strings.HasPrefix(value, "#") {
+				return ""
+			}
+			return value
+		}
+	}
+	return ""
+}
+
+// isHex reports whether value is a non-empty, even-length hex string
+func isHex(value string) bool {
+	_, err := hex.DecodeString(value)
+	return value != "" && err == nil
+}
+
+// fundAddress requests faucet funding for address and logs the outcome, reporting whether the funds arrived
+func fundAddress(ctx context.Context, client NimiqRPC, address string) bool {
+	_, err := requestAndConfirmFunding(ctx, client, address)
+	switch {
+	case errors.Is(err, errNotLeader):
+		log.Println("Skipping funding:", err)
+	case errors.Is(err, errDryRunSkipped):
+		// Already logged and counted by the dry-run guard
+	case errors.Is(err, errFaucetThrottled):
+		// Waiting out the throttle or backoff is expected, only worth a step log
+		stepf("Funding skipped: %v", err)
+	case err != nil:
+		log.Printf("Funding failed: %v", err)
+	default:
+		log.Println("Funded address successfully.")
+	}
+	return err == nil
+}
+
+// errConsensusLost is returned when consensus is lost right before a transaction would be sent
+var errConsensusLost = errors.New("consensus lost before sending transaction")
+
+// verifyConsensusBeforeSend re-checks consensus immediately before broadcasting a transaction
+func verifyConsensusBeforeSend(client NimiqRPC, address string) error {
+	consensus, err := client.IsConsensusEstablished()
+	if err != nil || !consensus {
+		prometheus.ValidatorTxConsensusAbortCounter.WithLabelValues(address).Inc()
+		if err != nil {
+			return fmt.Errorf("%w: %v", errConsensusLost, err)
 		}
+		return errConsensusLost
 	}
-	return "", fmt.Errorf("vote key not found in file")
+	return nil
 }
 
-func fundAddress(address string) bool {
-	// Preparing data as URL-encoded form data
-	data := url.Values{}
-	data.Set("address", address)
+// errAlreadyActive is returned when the validator turns out to exist right before sending an activation
+var errAlreadyActive = errors.New("validator already active")
+
+// errInsufficientForFee is returned when the sender cannot pay the validator deposit plus the transaction fee
+var errInsufficientForFee = errors.New("balance does not cover deposit plus fee")
 
-	// Making the HTTP POST request
-	resp, err := http.PostForm(faucetURL, data)
+// verifyBalanceCoversFee checks the sender can pay the deposit and fee, as a send at exactly the deposit fails on the fee
+func verifyBalanceCoversFee(client NimiqRPC, sender, address string, fee int) error {
+	balance, err := client.GetAccountBalanceByAddress(sender)
 	if err != nil {
-		log.Printf("Error posting to faucet: %v", err)
-		return false
+		return fmt.Errorf("error fetching sender balance: %w", err)
+	}
+	required := int64(minStakeNIM*lunaPerNIM) + int64(fee)
+	if balance < required {
+		prometheus.ValidatorInsufficientForFeeGauge.WithLabelValues(address).Set(1)
+		log.Printf("Balance of %s is %d Luna, %d Luna needed for the deposit plus a %d Luna fee. Not sending.", sender, balance, required, fee)
+		return errInsufficientForFee
 	}
-	defer resp.Body.Close()
+	prometheus.ValidatorInsufficientForFeeGauge.WithLabelValues(address).Set(0)
+	return nil
+}
 
-	// Checking for the HTTP response status code
-	if resp.StatusCode != http.StatusOK {
-		log.Printf("Faucet returned non-OK status: %d %s", resp.StatusCode, resp.Status)
-		return false
+// errChainStalled is returned when the node's block height does not advance right before sending
+var errChainStalled = errors.New("node block height is not advancing")
+
+// verifyChainAdvancing makes sure the node is still producing blocks, so "+0" validity is not based on a stalled head
+func verifyChainAdvancing(client NimiqRPC) error {
+	before, err := client.GetCurrentBlockNumber()
+	if err != nil {
+		return fmt.Errorf("error fetching block number: %w", err)
+	}
+	time.Sleep(chainAdvanceCheckInterval)
+	after, err := client.GetCurrentBlockNumber()
+	if err != nil {
+		return fmt.Errorf("error fetching block number: %w", err)
 	}
 
-	log.Println("Funded address successfully.")
-	return true
+	if after <= before {
+		prometheus.NimiqChainAdvancingGauge.Set(0)
+		log.Printf("Block height stuck at %d for %s.", after, chainAdvanceCheckInterval)
+		return errChainStalled
+	}
+	prometheus.NimiqChainAdvancingGauge.Set(1)
+	return nil
 }
 
-func activateValidator(client *rpc.Client, address string) bool {
-	log.Printf("Address: %s", address)
+// transactionSender returns the address paying for transactions, the validator itself unless SENDER_ADDRESS is set
+func transactionSender(validatorAddress string) string {
+	if senderAddress != "" {
+		return senderAddress
+	}
+	return validatorAddress
+}
 
-	sigKey, err := getPrivateKey("/keys/signing_key.txt")
+// importAndUnlock imports the private key for address from keyFile into the node wallet and unlocks it
+func importAndUnlock(client NimiqRPC, keyFile, address string) error {
+	privateKey, err := getPrivateKeyForAddress(keyFile, address)
 	if err != nil {
-		log.Println("Error getting signing key:", err)
-		return false
+		return fmt.Errorf("error getting private key for %s: %w", address, err)
 	}
 
-	voteKey, err := getVoteKey("/keys/vote_key.txt")
+	log.Printf("Importing raw key for %s.", address)
+	err = retryWalletOperation("import raw key", func() error {
+		if _, err := client.ImportRawKey(privateKey, ""); err != nil && !strings.Contains(strings.ToLower(err.Error()), "already") {
+			return err
+		}
+		return nil // A key that is already imported is fine
+	})
 	if err != nil {
-		log.Println("Error getting vote key:", err)
-		return false
+		return fmt.Errorf("failed to import raw key: %w", err)
 	}
 
-	addressPrivate, err := getPrivateKey("/keys/address.txt")
+	// Unlock the account
+	log.Printf("Unlocking account %s.", address)
+	err = retryWalletOperation("unlock account", func() error {
+		return client.UnlockAccount(address, "", unlockDurationSeconds)
+	})
 	if err != nil {
-		log.Println("Error getting address private key:", err)
-		return false
+		return fmt.Errorf("failed to unlock account: %w", err)
 	}
+	recordUnlock(address)
+	return nil
+}
 
-	log.Println("Importing raw key.")
-	_, err = client.ImportRawKey(addressPrivate, "")
+// Attempts and initial backoff of wallet operations, which can fail while the node wallet is still loading
+const (
+	walletRetryAttempts = 3
+	walletRetryBackoff  = 2 * time.Second
+)
+
+// retryWalletOperation retries a wallet operation that is safe to repeat with doubling backoff,
+// separately from RPC retries since it changes node wallet state
+func retryWalletOperation(name string, operation func() error) error {
+	backoff := walletRetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt == walletRetryAttempts {
+			return err
+		}
+		log.Printf("Attempt %d/%d to %s failed: %v. Retrying in %s...", attempt, walletRetryAttempts, name, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// newValidatorTransaction builds the new validator transaction, either through the node wallet
+// or, with TX_SIGNING_MODE=raw, as an unsigned transaction that is signed in a separate step
+func newValidatorTransaction(client NimiqRPC, sender, address, sigKey, voteKey, reward string, fee int) (string, error) {
+	if txSigningMode != "raw" {
+		rawTx, err := client.SendNewValidatorTransaction(sender, address, sigKey, voteKey, reward, "", fee, "+0")
+		if err != nil {
+			return "", fmt.Errorf("failed to create new validator transaction: %w", err)
+		}
+		return rawTx, nil
+	}
+
+	unsignedTx, err := client.CreateRawValidatorTransaction(sender, address, sigKey, voteKey, reward, "", fee, "+0")
 	if err != nil {
-		log.Println("Failed to import raw key:", err)
-		return false
+		return "", fmt.Errorf("failed to create raw validator transaction: %w", err)
+	}
+	signedTx, err := client.SignTransaction(unsignedTx, sender)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign validator transaction: %w", err)
 	}
+	return signedTx, nil
+}
 
-	// Unlock the account
-	log.Println("Unlocking account.")
-	if err := client.UnlockAccount(address, "", 0); err != nil {
-		log.Println("Failed to unlock account:", err)
-		return false
+// retryAfterReimport runs send and, when the node wallet lost the account meanwhile, re-imports
+// and unlocks its key once before retrying instead of waiting for the next activation cycle.
+// An account that was locked behind our back is unlocked again once before retrying.
+func retryAfterReimport(client NimiqRPC, keyFile, address string, send func() (string, error)) (string, error) {
+	result, err := send()
+	if rpc.IsAccountLocked(err) {
+		log.Printf("Account %s was locked unexpectedly, unlocking it again: %v", address, err)
+		prometheus.ValidatorUnexpectedLockCounter.Inc()
+		if err := client.UnlockAccount(address, "", unlockDurationSeconds); err != nil {
+			return "", fmt.Errorf("failed to unlock account: %w", err)
+		}
+		recordUnlock(address)
+		return send()
+	}
+	if !rpc.IsAccountNotFound(err) {
+		return result, err
+	}
+	log.Printf("Account %s missing from the node wallet, re-importing its key: %v", address, err)
+	if err := importAndUnlock(client, keyFile, address); err != nil {
+		return "", err
 	}
+	return send()
+}
 
-	log.Println("Activating Validator")
-	rawTx, err := client.SendNewValidatorTransaction(address, address, sigKey, voteKey, address, "", 500, "+0")
+func activateValidator(ctx context.Context, client NimiqRPC, address string) error {
+	if !isLeader() {
+		return errNotLeader
+	}
+	if skipForDryRun(dryRunActivation, "activate validator %s with fee %d Luna", address, txFeeLuna) {
+		return nil
+	}
+	prometheus.ValidatorActivationAttemptsSinceSuccessGauge.WithLabelValues(address).Inc()
+	log.Printf("Address: %s", address)
+
+	sigKey, err := getPrivateKey(keyPath("signing_key.txt"))
 	if err != nil {
-		log.Println("Failed to create new validator transaction:", err)
-		return false
+		return fmt.Errorf("error getting signing key: %w", err)
 	}
 
-	log.Println("Sending Transaction")
-	txHash, err := client.SendRawTransaction(rawTx)
+	voteKey, err := getVoteKey(keyPath("vote_key.txt"))
 	if err != nil {
-		log.Println("Failed to send raw transaction:", err)
-		return false
+		return fmt.Errorf("error getting vote key: %w", err)
 	}
 
-	log.Printf("Transaction sent successfully. Hash: %s", txHash)
+	if err := importAndUnlock(client, keyPath("address.txt"), address); err != nil {
+		return err
+	}
+	sender := transactionSender(address)
+	senderKey := keyPath("address.txt")
+	if sender != address {
+		senderKey = senderKeyFile
+		if err := importAndUnlock(client, senderKey, sender); err != nil {
+			return err
+		}
+	}
+
+	log.Println("Activating Validator")
+	reward := rewardAddress
+	if reward == "" {
+		reward = address
+	}
+
+	fee := txFeeLuna
+	var txHash string
+	var spentFee int
+	for attempt := 0; ; attempt++ {
+		// The guards run before every send, so a fee-bumped resend never duplicates a transaction that landed late.
+		// Never broadcast against a node that lost consensus since our last check.
+		if err := verifyConsensusBeforeSend(client, address); err != nil {
+			return err
+		}
+		if err := verifyChainAdvancing(client); err != nil {
+			return err
+		}
+
+		// Another process or an earlier in-flight transaction may have activated the validator meanwhile.
+		// Only a node confirming the validator does not exist makes sending the deposit safe.
+		_, err = client.GetValidatorByAddress(address)
+		if err == nil && attempt > 0 {
+			log.Printf("Activation transaction %s landed after the confirmation timeout, not resending", txHash)
+			break
+		}
+		if err == nil {
+			prometheus.ValidatorActivationRaceCounter.WithLabelValues(address).Inc()
+			return errAlreadyActive
+		}
+		if !errors.Is(err, rpc.ErrValidatorNotFound) {
+			return fmt.Errorf("cannot confirm the validator does not exist yet, not sending the deposit: %w", err)
+		}
+
+		if err := verifyBalanceCoversFee(client, sender, address, fee); err != nil {
+			return err
+		}
+		if err := confirmLeadership(); err != nil {
+			return err
+		}
+		rawTx, err := retryAfterReimport(client, senderKey, sender, func() (string, error) {
+			return newValidatorTransaction(client, sender, address, sigKey, voteKey, reward, fee)
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Println("Sending Transaction")
+		txHash, err = client.SendRawTransaction(rawTx)
+		if err != nil {
+			return fmt.Errorf("failed to send raw transaction: %w", err)
+		}
+
+		log.Printf("Transaction sent successfully. Hash: %s", txHash)
+		spentFee = fee
+
+		if confirmationTimeout <= 0 || waitForConfirmation(ctx, client, txHash, confirmationTimeout) {
+			break
+		}
+		// Shutting down is no reason to pay a higher fee
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Not confirmed in time, resend with a higher fee if allowed
+		bumped := nextBumpedFee(fee)
+		if attempt >= feeBumpMaxAttempts || bumped <= fee {
+			return fmt.Errorf("activation transaction %s not confirmed within %s", txHash, confirmationTimeout)
+		}
+		log.Printf("Activation transaction %s not confirmed within %s. Resending with fee %d Luna (was %d).", txHash, confirmationTimeout, bumped, fee)
+		prometheus.ValidatorTxFeeBumpsCounter.WithLabelValues(address).Inc()
+		fee = bumped
+	}
 
 	prometheus.ValidatorActivatedGauge.WithLabelValues(address).Set(1)
 	prometheus.ValidatorActivatedCounterGauge.WithLabelValues(address).Inc()
-	return true
+	prometheus.ValidatorActivationAttemptsSinceSuccessGauge.WithLabelValues(address).Set(0)
+	prometheus.ActivatorFeesSpentCounter.WithLabelValues("activation").Add(float64(spentFee))
+	notifyWebhook(client, webhookActivation, address, txHash)
+	return nil
 }
 
-func reActivateValidator(client *rpc.Client, address string) bool {
-	log.Printf("Address: %s", address)
+// Causes a reactivation is labeled with in nimiq_validator_reactivations_total. Retirement is the
+// only reactivation the activator performs, other causes get their own label once they are handled.
+const (
+	reactivationRetired = "retired"
+)
 
-	sigKey, err := getPrivateKey("/keys/signing_key.txt")
-	if err != nil {
-		log.Println("Error getting signing key:", err)
-		return false
+func reActivateValidator(client NimiqRPC, address, cause string) error {
+	if !isLeader() {
+		return errNotLeader
+	}
+	if skipForDryRun(dryRunReactivation, "reactivate validator %s (%s) with fee %d Luna", address, cause, txFeeLuna) {
+		return nil
 	}
+	log.Printf("Address: %s", address)
 
-	addressPrivate, err := getPrivateKey("/keys/address.txt")
+	sigKey, err := getPrivateKey(keyPath("signing_key.txt"))
 	if err != nil {
-		log.Println("Error getting address private key:", err)
-		return false
+		return fmt.Errorf("error getting signing key: %w", err)
 	}
 
-	log.Println("Importing raw key.")
-	_, err = client.ImportRawKey(addressPrivate, "")
-	if err != nil {
-		log.Println("Failed to import raw key:", err)
-		return false
+	sender := transactionSender(address)
+	keyFile := keyPath("address.txt")
+	if sender != address {
+		keyFile = senderKeyFile
+	}
+	if err := importAndUnlock(client, keyFile, sender); err != nil {
+		return err
 	}
 
-	// Unlock the account
-	log.Println("Unlocking account.")
-	if err := client.UnlockAccount(address, "", 0); err != nil {
-		log.Println("Failed to unlock account:", err)
-		return false
+	// Never broadcast against a node that lost consensus since our last check
+	if err := verifyConsensusBeforeSend(client, address); err != nil {
+		return err
+	}
+	if err := verifyChainAdvancing(client); err != nil {
+		return err
 	}
 
 	log.Println("Activating Validator")
-	txHash, err := client.SendReactivateValidatorTransaction(address, address, sigKey, 500, "+0")
+	if err := confirmLeadership(); err != nil {
+		return err
+	}
+	txHash, err := retryAfterReimport(client, keyFile, sender, func() (string, error) {
+		return client.SendReactivateValidatorTransaction(sender, address, sigKey, txFeeLuna, "+0")
+	})
 	if err != nil {
-		log.Println("Failed to reactivate", err)
-		return false
+		return fmt.Errorf("failed to reactivate: %w", err)
 	}
 
 	log.Printf("Transaction sent successfully. Hash: %s", txHash)
 
 	prometheus.ValidatorReActivatedCounterGauge.WithLabelValues(address).Inc()
-	return true
+	prometheus.ValidatorReactivationsCounter.WithLabelValues(address, cause).Inc()
+	prometheus.ActivatorFeesSpentCounter.WithLabelValues("reactivation").Add(float64(txFeeLuna))
+	notifyWebhook(client, webhookReactivation, address, txHash)
+	return nil
 }
 
 func updateValidatorMetrics(address string, details *rpc.ValidatorDetails) {
-	// Update balance
-	prometheus.NimiqTotalStakeGauge.WithLabelValues(address).Set(float64(details.Balance))
+	// Update balance, skipped when the node reports a null balance so we never publish a bogus 0
+	if details.Balance != nil {
+		prometheus.NimiqTotalStakeGauge.WithLabelValues(address).Set(float64(*details.Balance))
+	} else {
+		log.Printf("Validator balance missing from node response, keeping previous stake metric.")
+	}
+
+	// Split the balance into own deposit and delegated stake when the node reports the deposit
+	if details.Deposit != nil {
+		prometheus.ValidatorDepositGauge.WithLabelValues(address).Set(float64(*details.Deposit))
+		if details.Balance != nil {
+			prometheus.ValidatorDelegatedStakeGauge.WithLabelValues(address).Set(float64(*details.Balance - *details.Deposit))
+		}
+	}
 
 	// Update number of stakers
 	prometheus.ValidatorNumStakersGauge.WithLabelValues(address).Set(float64(details.NumStakers))
 
-	// Update inactivity flag, default to 0 if nil
-	inactivityFlag := float64(0)
+	// The inactivity flag is the block the validator is inactive from, nil while it is active
+	inactiveFrom, inactive := float64(0), float64(0)
 	if details.InactivityFlag != nil {
-		inactivityFlag = float64(*details.InactivityFlag)
+		inactiveFrom = float64(*details.InactivityFlag)
+		inactive = 1
 	}
-	prometheus.ValidatorInactivityFlagGauge.WithLabelValues(address).Set(inactivityFlag)
+	prometheus.ValidatorInactivityFlagGauge.WithLabelValues(address).Set(inactiveFrom)
+	prometheus.ValidatorInactiveFromBlockGauge.WithLabelValues(address).Set(inactiveFrom)
+	prometheus.ValidatorInactiveGauge.WithLabelValues(address).Set(inactive)
 
 	// Update retired status, 1 if true, 0 otherwise
 	retired := float64(0)
@@ -288,162 +778,463 @@ func updateValidatorMetrics(address string, details *rpc.ValidatorDetails) {
 	// validator is active when reaches this point
 	prometheus.ValidatorActivatedGauge.WithLabelValues(address).Set(1)
 
-	log.Printf("Validator Prometheus metrics updated.")
+	stepf("Validator Prometheus metrics updated.")
+}
+
+// stakersCache holds the summed staker balances between refreshes of the expensive staker list
+var stakersCache struct {
+	sync.Mutex
+	total     int64
+	fetchedAt time.Time
+}
+
+// stakersTotal returns the summed staker balances, refetching the staker list at most every STAKERS_REFRESH_INTERVAL
+func stakersTotal(client NimiqRPC, address string) (int64, error) {
+	stakersCache.Lock()
+	defer stakersCache.Unlock()
+	if !stakersCache.fetchedAt.IsZero() && time.Since(stakersCache.fetchedAt) < stakersRefreshInterval {
+		return stakersCache.total, nil
+	}
+	total, err := client.GetTotalStakeByValidatorAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	stakersCache.total, stakersCache.fetchedAt = total, time.Now()
+	return total, nil
+}
+
+// updateStakeReconciliation exposes how far the node-reported validator balance is from the
+// sum of its stakers' balances, a persistent difference points at a node or parsing bug
+func updateStakeReconciliation(client NimiqRPC, address string, details *rpc.ValidatorDetails) {
+	if stakersUnsupported.Load() || details.Balance == nil {
+		return
+	}
+	total, err := stakersTotal(client, address)
+	if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getStakersByValidatorAddress, disabling stake reconciliation:", err)
+			stakersUnsupported.Store(true)
+			return
+		}
+		log.Println("Error fetching validator stakers:", err)
+		return
+	}
+	prometheus.ValidatorStakeReconciliationDiffGauge.WithLabelValues(address).Set(float64(*details.Balance - total))
+}
+
+// updateParkedGauge reports whether the validator is parked, which often precedes jailing
+func updateParkedGauge(client NimiqRPC, address string) {
+	if parkedSetUnsupported.Load() {
+		return
+	}
+	parked, err := client.GetParkedValidators()
+	if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getParkedValidators, disabling parked gauge:", err)
+			parkedSetUnsupported.Store(true)
+			return
+		}
+		log.Println("Error fetching parked validators:", err)
+		return
+	}
+
+	isParked := float64(0)
+	for _, parkedAddress := range parked {
+		if parkedAddress == address {
+			isParked = 1
+			stepf("Validator is in the parked set.")
+			break
+		}
+	}
+	prometheus.ValidatorParkedGauge.WithLabelValues(address).Set(isParked)
 }
 
-func checkSufficientBalance(client *rpc.Client, address string) (bool, float64) {
+// updateActiveValidatorCount reports the size of the active validator set
+func updateActiveValidatorCount(client NimiqRPC) {
+	if activeSetUnsupported.Load() {
+		return
+	}
+	validators, err := client.GetActiveValidators()
+	if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getActiveValidators, disabling active validator count:", err)
+			activeSetUnsupported.Store(true)
+			return
+		}
+		log.Println("Error fetching active validators:", err)
+		return
+	}
+	prometheus.ActiveValidatorCountGauge.Set(float64(len(validators)))
+}
+
+// updateStakerMetrics exports the stake of STAKER_ADDRESS and whether it is delegated to the validator
+func updateStakerMetrics(client NimiqRPC, validatorAddress string) {
+	if stakerAddress == "" || stakerUnsupported.Load() {
+		return
+	}
+	staker, err := client.GetStakerByAddress(stakerAddress)
+	if errors.Is(err, rpc.ErrStakerNotFound) {
+		staker = &rpc.StakerDetails{Address: stakerAddress} // Nothing staked
+	} else if err != nil {
+		if rpc.IsMethodNotFound(err) {
+			log.Println("Node does not support getStakerByAddress, disabling staker metrics:", err)
+			stakerUnsupported.Store(true)
+			return
+		}
+		log.Println("Error fetching staker details:", err)
+		return
+	}
+
+	prometheus.StakerBalanceGauge.WithLabelValues(stakerAddress).Set(float64(staker.Balance))
+	prometheus.StakerInactiveBalanceGauge.WithLabelValues(stakerAddress).Set(float64(staker.InactiveBalance))
+	prometheus.StakerRetiredBalanceGauge.WithLabelValues(stakerAddress).Set(float64(staker.RetiredBalance))
+	delegates := float64(0)
+	if staker.Delegation != nil && sameAddress(*staker.Delegation, validatorAddress) {
+		delegates = 1
+	}
+	prometheus.StakerDelegatesToValidatorGauge.WithLabelValues(stakerAddress).Set(delegates)
+}
+
+// balanceGateAddress returns the address whose balance gates activation of the validator,
+// BALANCE_CHECK_ADDRESS when the deposit is paid from another account
+func balanceGateAddress(validatorAddress string) string {
+	if balanceCheckAddress != "" {
+		return balanceCheckAddress
+	}
+	return validatorAddress
+}
+
+// checkSufficientBalance reports whether the gating account of the validator covers the stake and fee,
+// along with its balance in NIM
+func checkSufficientBalance(client NimiqRPC, validatorAddress string) (bool, float64) {
+	address := balanceGateAddress(validatorAddress)
 	balance, err := client.GetAccountBalanceByAddress(address)
 	if err != nil {
 		log.Println("Error fetching account balance:", err)
 		return false, 0
 	}
-	balanceInNim := float64(balance) / 100000.0
-	prometheus.ValidatorBalanceGauge.WithLabelValues(address).Set(float64(balance))
-	return balanceInNim >= 100000.0, balanceInNim
+	balanceInNim := float64(balance) / lunaPerNIM
+	recordBalance(address, balance)
+	return balanceInNim >= minStakeNIM+float64(txFeeLuna)/lunaPerNIM, balanceInNim
 }
 
-func checkActive(client *rpc.Client, address string) bool {
-	validatorDetails, err := client.GetValidatorByAddress(address)
+// updateBalanceMetrics refreshes the balance gauges of all given addresses in one round trip
+func updateBalanceMetrics(client NimiqRPC, addresses ...string) {
+	balances, err := client.GetAccountBalances(addresses)
 	if err != nil {
-		log.Println("Error fetching validator details:", err)
-		return false
+		log.Println("Error fetching account balances:", err)
+		return
 	}
-	// Check if the validator's address matches the input address
-	// Assuming ValidatorDetails struct has an Address field
-	if validatorDetails.Address != address {
-		return false // Address does not match or validator not found
+	for address, balance := range balances {
+		recordBalance(address, balance)
 	}
-	// Check if the balance is above the threshold and address matches
-	isActive := validatorDetails.Address == address
-	return isActive
 }
 
-func periodicUpdates(client *rpc.Client, address string) {
-	ticker := time.NewTicker(10 * time.Second)
+// checkActive reports whether the validator exists on chain. An active validator's balance is locked
+// as deposit, so its account balance alone says nothing about whether it needs funding.
+// Errors other than the validator not being found leave its state unknown.
+func checkActive(client NimiqRPC, address string) (bool, error) {
+	validatorDetails, err := client.GetValidatorByAddress(address)
+	if errors.Is(err, rpc.ErrValidatorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return sameAddress(validatorDetails.Address, address), nil
+}
+
+// periodicUpdates polls every interval until the balance suffices and the validator is handled,
+// funding the address in the meantime. It returns early when ctx is canceled and with
+// errRunLimitNotActive when MAX_ITERATIONS or MAX_RUNTIME is hit first.
+func periodicUpdates(ctx context.Context, client NimiqRPC, address string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
 		sufficient, currentBalance := checkSufficientBalance(client, address)
-		isActive := checkActive(client, address)
+		isActive, activeErr := checkActive(client, address)
+		if activeErr != nil {
+			log.Println("Error fetching validator details, not funding until the validator state is known:", activeErr)
+		}
+		// Make the funding decision visible: faucet funding only happens on testnet with a faucet configured
+		faucetEnabled := network == "testnet" && faucetURL != ""
+		enabled, eligible := float64(0), float64(0)
+		if faucetEnabled {
+			enabled = 1
+			if !sufficient && !isActive && activeErr == nil && !faucetInCooldown() {
+				eligible = 1
+			}
+		}
+		prometheus.FaucetEnabledGauge.Set(enabled)
+		prometheus.FaucetEligibleGauge.Set(eligible)
 
 		if sufficient || isActive {
 			log.Printf("Sufficient balance detected: %.0f NIM. Checking validator status...", currentBalance)
-			if checkAndHandleValidatorStatus(client, address) {
+			if checkAndHandleValidatorStatus(ctx, client, address) {
 				log.Printf("Validator status checked and handled.")
-				return // Exit the loop if the validator is activated or metrics are updated
+				return nil // Exit the loop if the validator is activated or metrics are updated
 			}
 		} else {
-			if network == "testnet" {
-				if fundAddress(address) {
+			summary.setState("funding")
+			switch {
+			case activeErr != nil:
+				// Never fund a validator that may already be active
+			case validatorDeleted.Load():
+				log.Printf("Validator %s was deleted, parked without funding or top-ups.", address)
+			case faucetEnabled:
+				if fundAddress(ctx, client, balanceGateAddress(address)) {
 					log.Printf("Funded address successfully.")
 				} else {
 					log.Printf("Failed to fund address.")
 				}
+			case topUpEnabled:
+				if err := topUpFromFundingAccount(client, balanceGateAddress(address), currentBalance); err != nil {
+					log.Println("Top-up failed:", err)
+				}
+			}
+			stakeNeeded := minStakeNIM - currentBalance
+			log.Printf("Insufficient balance. %.0f/%.0f NIM. missing %.0f Waiting %s for next check...", currentBalance, minStakeNIM, stakeNeeded, interval)
+			if eta := estimatedSecondsToThreshold(balanceGateAddress(address)); eta > 0 {
+				log.Printf("At the current growth rate the balance is sufficient in about %s.", time.Duration(eta*float64(time.Second)).Round(time.Second))
 			}
-			stakeNeeded := 100000 - currentBalance
-			log.Printf("Insufficient balance. %.0f/100 000 NIM. missing %.0f Waiting %d seconds for next check...", currentBalance, stakeNeeded, 10)
+		}
+		if runLimitReached() {
+			return runLimitResult(false)
 		}
 	}
 }
 
-func checkAndHandleValidatorStatus(client *rpc.Client, address string) bool {
-	const blocksForReactivation = 8000
+// retirementNotified is set once a retirement was reported to the webhook, until the validator is active again
+var retirementNotified atomic.Bool
 
+func checkAndHandleValidatorStatus(ctx context.Context, client NimiqRPC, address string) bool {
 	details, err := client.GetValidatorByAddress(address)
 	if err != nil {
+		if isValidatorDeleted(address, err) {
+			summary.setState("deleted")
+			prometheus.ValidatorActivationNeededGauge.WithLabelValues(address).Set(0)
+			return true
+		}
+		// Only a node positively reporting the validator missing warrants activation, never a failed request
+		if !errors.Is(err, rpc.ErrValidatorNotFound) {
+			log.Println("Error fetching validator details:", err)
+			return false
+		}
 		log.Println("Validator not active. Needs activation:", err)
-		activateValidator(client, address)
+		summary.setState("inactive")
+		prometheus.ValidatorActivationNeededGauge.WithLabelValues(address).Set(1)
+		if !activationEnabled {
+			log.Printf("Activation disabled. Not activating validator %s.", address)
+			return false
+		}
+		summary.setAction("activate")
+		if err := activateValidator(ctx, client, address); errors.Is(err, errAlreadyActive) {
+			log.Printf("Validator became active before sending, skipping activation.")
+			summary.setAction("none")
+		} else if err != nil {
+			log.Println("Activation failed:", err)
+			summary.setAction("activate_failed")
+		}
 		return false
 	}
 
+	markValidatorSeen()
 	// Update metrics regardless of the validator's status
 	updateValidatorMetrics(address, details)
+	updateParkedGauge(client, address)
+	updateStakeReconciliation(client, address, details)
 
 	// Check if the validator is retired or jailed and handle accordingly
 	if details.Retired {
+		summary.setState("retired")
+		if !retirementNotified.Swap(true) {
+			notifyWebhook(client, webhookRetirement, address, "")
+		}
+		if !autoReactivateRetired {
+			log.Printf("Validator is retired. Automatic reactivation of retired validators is disabled, leaving it retired.")
+			prometheus.ValidatorActivationNeededGauge.WithLabelValues(address).Set(0)
+			return true
+		}
 		log.Printf("Validator is retired. Needs reactivation.")
-		reActivateValidator(client, address)
+		prometheus.ValidatorActivationNeededGauge.WithLabelValues(address).Set(1)
+		if !activationEnabled {
+			log.Printf("Activation disabled. Not reactivating validator %s.", address)
+			return false
+		}
+		if isJailEscalated() {
+			log.Printf("Jail escalation active. Skipping automatic reactivation of the retired validator.")
+			return false
+		}
+		summary.setAction("reactivate")
+		if err := reActivateValidator(client, address, reactivationRetired); err != nil {
+			log.Println("Reactivation failed:", err)
+			summary.setAction("reactivate_failed")
+		}
 		return false
 	}
+	retirementNotified.Store(false)
+	prometheus.ValidatorActivationNeededGauge.WithLabelValues(address).Set(0)
 
 	currentBlockNumber, err := client.GetCurrentBlockNumber()
 	if err != nil {
 		log.Println("Error fetching current block number:", err)
 		return false
 	}
+	summary.setBlockNumber(currentBlockNumber)
 
 	if details.JailedFrom != nil {
+		if recordJailEvent(address, *details.JailedFrom) {
+			notifyWebhook(client, webhookJailing, address, "")
+		}
 		blocksSinceJailed := currentBlockNumber - int64(*details.JailedFrom)
-		if blocksSinceJailed < blocksForReactivation {
-			// Validator is considered still jailed if the difference is less than 8000 blocks
-			log.Printf("Validator is still within the jailed period. Blocks since jailed: %d", blocksSinceJailed)
+		prometheus.ValidatorJailedDurationBlocksGauge.WithLabelValues(address).Set(float64(blocksSinceJailed))
+		prometheus.ValidatorJailBlocksRemainingGauge.WithLabelValues(address).Set(float64(max(int64(jailReleaseBlocks)-blocksSinceJailed, 0)))
+		if blocksSinceJailed < int64(jailReleaseBlocks) {
+			// Validator is considered still jailed if the difference is less than jailReleaseBlocks
+			stepf("Validator is still within the jailed period. Blocks since jailed: %d", blocksSinceJailed)
+			summary.setState("jailed")
 			prometheus.ValidatorJailedGauge.WithLabelValues(address).Set(1)
 			prometheus.ValidatorJailedFromGauge.WithLabelValues(address).Set(float64(*details.JailedFrom))
+			return false
 		} else {
 			prometheus.ValidatorJailedGauge.WithLabelValues(address).Set(0)
-			// If reactivation or further action is required when a validator is no longer considered jailed, add that logic here.
+			// Jailed validators are never reactivated automatically, so JAIL_ESCALATION_THRESHOLD has nothing to gate here.
+			// It only stops the reactivation of retired validators above.
 		}
 	}
 	prometheus.ValidatorJailedGauge.WithLabelValues(address).Set(0)
 	prometheus.ValidatorJailedFromGauge.WithLabelValues(address).Set(0)
-	log.Printf("Validator is active and in good standing.")
+	if details.JailedFrom == nil {
+		prometheus.ValidatorJailedDurationBlocksGauge.WithLabelValues(address).Set(0)
+		prometheus.ValidatorJailBlocksRemainingGauge.WithLabelValues(address).Set(0)
+	}
+	recordJailRecovery(address)
+	summary.setState("active")
+	stepf("Validator is active and in good standing.")
 	return true
 }
 
-func main() {
-	const appVersion = "1.0.0"
-	client := rpc.NewClient()
-
-	log.Printf("Starting Nimiq Validator Activator v%s on port %s\n", appVersion, servingPort)
-
-	go func() {
-		http.Handle("/metrics", promhttp.Handler())
-		log.Printf("Prometheus metrics server running on port %s", servingPort)
-		if err := http.ListenAndServe(servingPort, nil); err != nil {
-			log.Fatalf("Error starting Prometheus HTTP server: %v", err)
-		}
-	}()
+// runStartupSequence checks the endpoint and consensus, then resolves the validator address
+func runStartupSequence(client NimiqRPC) (string, error) {
+	// Fail with a clear message when the endpoint is wrong instead of a cryptic mid-loop decode error
+	if err := client.Ping(); err != nil {
+		return "", fmt.Errorf("node RPC endpoint check failed: %w", err)
+	}
 
 	if !checkConsensus(client) {
-		log.Printf("Failed to establish consensus. Exiting...")
-		return
+		return "", errNoConsensus
 	}
 
 	updateEpochNumberGauge(client)
 
-	validatorAddress, err := client.GetAddress()
+	validatorAddress, err := resolveValidatorAddress(client)
 	if err != nil {
-		log.Println("Error fetching validator address:", err)
-		return
+		return "", err
 	}
-	log.Println("Validator address:", validatorAddress)
-	prometheus.ValidatorActivatedGauge.WithLabelValues(validatorAddress).Set(0)
-	prometheus.ValidatorActivatedCounterGauge.WithLabelValues(validatorAddress).Set(0)
+	return validatorAddress, nil
+}
 
-	_, err = client.GetValidatorByAddress(validatorAddress)
-	if err != nil {
-		log.Println("Validator not active. Needs activation:", err)
-		sufficientBalance, currentBalance := checkSufficientBalance(client, validatorAddress)
-		if sufficientBalance {
-			log.Printf("Sufficient Balance detected: %.2f NIM. Checking validator status...", currentBalance)
-			checkAndHandleValidatorStatus(client, validatorAddress)
-		} else {
-			balanceNeeded := 100000.0 - currentBalance
-			log.Printf("Initial balance insufficient: %.0f NIM needed to reach 100k NIM.", balanceNeeded)
-			periodicUpdates(client, validatorAddress)
+// errNoConsensus is returned by the startup sequence while the node has not established consensus
+var errNoConsensus = errors.New("failed to establish consensus")
+
+// startupWithRetry retries the startup sequence with backoff so a node that is still booting
+// does not crash-loop the activator, giving up once STARTUP_DEADLINE has passed unless
+// ON_NO_CONSENSUS=retry and the node is only missing consensus
+func startupWithRetry(ctx context.Context, client NimiqRPC) (string, error) {
+	deadline := time.Now().Add(startupDeadline)
+	backoff := 5 * time.Second
+	for attempt := 1; ; attempt++ {
+		validatorAddress, err := runStartupSequence(client)
+		if err == nil {
+			return validatorAddress, nil
+		}
+		// With ON_NO_CONSENSUS=retry a slowly syncing node is waited for indefinitely
+		waitForConsensus := onNoConsensus == "retry" && errors.Is(err, errNoConsensus)
+		if !waitForConsensus && time.Now().Add(backoff).After(deadline) {
+			return "", fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+		log.Printf("Startup attempt %d failed: %v. Retrying in %s...", attempt, err, backoff)
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(backoff):
 		}
+		backoff = min(backoff*2, time.Minute)
 	}
+}
 
-	ticker := time.NewTicker(15 * time.Second)
-	defer ticker.Stop()
+// consecutiveFailures counts main loop iterations in a row that did not complete their checks
+var consecutiveFailures atomic.Int64
+
+// recordIteration tracks the outcome of a main loop iteration
+func recordIteration(success bool) {
+	failures := int64(0)
+	if success {
+		consecutiveFailures.Store(0)
+	} else {
+		failures = consecutiveFailures.Add(1)
+	}
+	prometheus.ActivatorConsecutiveFailuresGauge.Set(float64(failures))
+}
+
+func main() {
+	configPath := flag.String("config", "", "Path to a YAML config file, environment variables override its values")
+	flag.Parse()
+
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath); err != nil {
+			log.Fatalf("Failed to load config file: %v", err)
+		}
+	}
+	loadConfig()
+
+	client := rpc.NewClient()
+	defer client.Close()
+	client.UserAgent = userAgent
+	client.SetMaxInFlight(rpcMaxInFlight)
+	// Fixtures let the full lifecycle run deterministically against recorded node behavior
+	if rpcReplayPath != "" {
+		transport, err := rpc.NewReplayTransport(rpcReplayPath)
+		if err != nil {
+			log.Fatalf("Failed to load RPC fixture: %v", err)
+		}
+		client.SetTransport(transport)
+	} else if rpcRecordPath != "" {
+		client.SetTransport(rpc.NewRecordingTransport(client.Transport(), rpcRecordPath))
+	}
 
-	for range ticker.C {
-		updateEpochNumberGauge(client)
-		state := checkAndHandleValidatorStatus(client, validatorAddress)
-		if !state {
-			log.Printf("Something went wrong. with the validator!")
+	if flag.Arg(0) == "simulate" {
+		if err := runSimulation(); err != nil {
+			log.Fatalf("Simulation failed: %v", err)
 		}
-		_, _ = checkSufficientBalance(client, validatorAddress)
+		log.Printf("Simulation passed.")
+		return
 	}
 
+	if flag.Arg(0) == "selftest" {
+		if err := runSelfTest(client); err != nil {
+			log.Fatalf("Self-test failed: %v", err)
+		}
+		return
+	}
+
+	if flag.Arg(0) == "dump-metrics" {
+		if err := dumpMetrics(client); err != nil {
+			log.Fatalf("Failed to dump metrics: %v", err)
+		}
+		return
+	}
+
+	// Run until interrupted, shutting down the loops and the HTTP server gracefully
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := Run(ctx, client, currentConfig()); err != nil {
+		log.Fatalf("Exiting: %v", err)
+	}
 }
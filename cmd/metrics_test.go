@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"testing"
+)
+
+// TestUpdateValidatorMetricsNullBalance checks that a null balance keeps the last known stake instead of reporting 0
+func TestUpdateValidatorMetricsNullBalance(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	balance := int64(42)
+	updateValidatorMetrics(address, &rpc.ValidatorDetails{Address: address, Balance: &balance})
+	updateValidatorMetrics(address, &rpc.ValidatorDetails{Address: address})
+
+	if got := metricValue(prometheus.NimiqTotalStakeGauge.WithLabelValues(address)); got != 42 {
+		t.Errorf("stake gauge = %v after a null balance, want 42", got)
+	}
+}
+
+// TestCheckValidatorStatusNotFound checks that a validator the node does not know is handled as missing, not dereferenced
+func TestCheckValidatorStatusNotFound(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	activationEnabled = false
+	if checkAndHandleValidatorStatus(context.Background(), &mockNode{address: address}, address) {
+		t.Error("missing validator reported as in good standing")
+	}
+	if got := metricValue(prometheus.ValidatorActivationNeededGauge.WithLabelValues(address)); got != 1 {
+		t.Errorf("activation needed gauge = %v, want 1", got)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"log"
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// currentEpoch is the latest epoch seen by updateEpochNumberGauge, rewardEpoch the last one rewards were checked in
+	currentEpoch atomic.Int64
+	rewardEpoch  int
+	rewardMu     sync.Mutex // Held for a whole reward update so an epoch is never looked up twice
+)
+
+// updateEpochReward exposes the rewards credited to our reward address for the epoch that just ended.
+// Rewards are paid at the macro block ending every batch, so the "reward" inherents of all macro
+// blocks of the finished epoch are added up. An epoch without any reward for us is reported as 0.
+func updateEpochReward(client NimiqRPC, address string) {
+	epoch := int(currentEpoch.Load())
+	if epochRewardUnsupported.Load() || epoch == 0 {
+		return
+	}
+	rewardMu.Lock()
+	defer rewardMu.Unlock()
+	if rewardEpoch == 0 {
+		rewardEpoch = epoch // First observation, wait for the next epoch change
+		return
+	}
+	if epoch <= rewardEpoch {
+		return
+	}
+
+	finishedEpoch := epoch - 1
+	electionBlock, err := client.GetElectionBlockOf(finishedEpoch)
+	if err != nil {
+		handleEpochRewardError("Error fetching election block:", err)
+		return
+	}
+	// The previous election block is where the finished epoch started, the genesis epoch has no batches before it
+	epochStart := electionBlock
+	if finishedEpoch > 0 {
+		epochStart, err = client.GetElectionBlockOf(finishedEpoch - 1)
+		if err != nil {
+			handleEpochRewardError("Error fetching election block:", err)
+			return
+		}
+	}
+	if (electionBlock-epochStart)%int64(blocksPerBatch) != 0 {
+		log.Printf("Epoch %d spans blocks %d to %d, which is not a whole number of %d block batches. Check BLOCKS_PER_BATCH.", finishedEpoch, epochStart, electionBlock, blocksPerBatch)
+		return
+	}
+
+	target := rewardAddress
+	if target == "" {
+		target = address
+	}
+	var reward int64
+	for macroBlock := min(epochStart+int64(blocksPerBatch), electionBlock); macroBlock <= electionBlock; macroBlock += int64(blocksPerBatch) {
+		inherents, err := client.GetInherentsByBlockNumber(macroBlock)
+		if err != nil {
+			handleEpochRewardError("Error fetching inherents:", err)
+			return
+		}
+		reward += sumRewards(inherents, target)
+	}
+	rewardEpoch = epoch
+
+	log.Printf("Epoch %d ended at block %d, reward received: %d Luna.", finishedEpoch, electionBlock, reward)
+	prometheus.ValidatorEpochRewardGauge.WithLabelValues(address).Set(float64(reward))
+}
+
+// sumRewards adds up the reward inherents paid to target
+func sumRewards(inherents []rpc.Inherent, target string) int64 {
+	var total int64
+	for _, inherent := range inherents {
+		if inherent.Type == "reward" && sameAddress(inherent.Target, target) {
+			total += inherent.Value
+		}
+	}
+	return total
+}
+
+func handleEpochRewardError(message string, err error) {
+	if rpc.IsMethodNotFound(err) {
+		log.Println("Node does not support epoch reward lookups, disabling epoch reward gauge:", err)
+		epochRewardUnsupported.Store(true)
+		return
+	}
+	log.Println(message, err)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"testing"
+)
+
+// rewardNode pays one Luna to every target at each macro block and a decoy reward in all other blocks
+type rewardNode struct {
+	mockNode
+	queried int
+}
+
+func (n *rewardNode) GetInherentsByBlockNumber(blockNumber int64) ([]rpc.Inherent, error) {
+	n.queried++
+	if blockNumber%int64(blocksPerBatch) != 0 {
+		return []rpc.Inherent{{Type: "reward", Target: n.address, Value: 1000}}, nil
+	}
+	return []rpc.Inherent{
+		{Type: "reward", Target: n.address, Value: 1},
+		{Type: "reward", Target: "NQ28 1111 1111 1111 1111 1111 1111 1111 1111", Value: 1},
+		{Type: "penalize", Target: n.address, Value: 1},
+	}, nil
+}
+
+func TestUpdateEpochReward(t *testing.T) {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	tests := []struct {
+		name           string
+		blocksPerBatch int
+		wantReward     float64
+		wantQueried    int
+	}{
+		// The mock node's epochs are 43200 blocks long
+		{"every batch of the epoch", 60, 720, 720},
+		{"longer batches", 43200, 1, 1},
+		{"batch length not dividing the epoch", 7, 0, 0},
+	}
+	rewardAddress = ""
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blocksPerBatch = test.blocksPerBatch
+			prometheus.ValidatorEpochRewardGauge.WithLabelValues(address).Set(0)
+			node := &rewardNode{mockNode: mockNode{address: address}}
+			rewardEpoch = 2
+			currentEpoch.Store(3)
+			updateEpochReward(node, address)
+
+			if got := metricValue(prometheus.ValidatorEpochRewardGauge.WithLabelValues(address)); got != test.wantReward {
+				t.Errorf("epoch reward = %v, want %v", got, test.wantReward)
+			}
+			if node.queried != test.wantQueried {
+				t.Errorf("queried %d blocks, want %d", node.queried, test.wantQueried)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"nimiq-validator-activator/prometheus"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Config holds the settings Run uses to drive its loops and HTTP server. All other settings are
+// read by the lifecycle functions from the package-level configuration filled by loadConfig.
+type Config struct {
+	ServingPort         string
+	PollInterval        time.Duration
+	FundingPollInterval time.Duration
+}
+
+// currentConfig returns the Run settings of the loaded configuration
+func currentConfig() Config {
+	return Config{
+		ServingPort:         servingPort,
+		PollInterval:        pollInterval,
+		FundingPollInterval: fundingPollInterval,
+	}
+}
+
+// Run serves the HTTP endpoints, resolves the validator and keeps it funded and active until ctx
+// is canceled or MAX_ITERATIONS or MAX_RUNTIME is reached. It returns nil after a graceful shutdown
+// or a limited run that left the validator active, and an error when startup fails or a limited
+// run ends with the validator inactive.
+func Run(ctx context.Context, client NimiqRPC, cfg Config) error {
+	log.Printf("Starting Nimiq Validator Activator v%s on port %s\n", appVersion, cfg.ServingPort)
+	exportConfigMetrics()
+	exportCanActMetric(exportKeyFileMetrics())
+	if err := checkExpectedNetwork(); err != nil {
+		return fmt.Errorf("network mismatch: %w", err)
+	}
+	startWebhookNotifier()
+	startLeaderElection(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", requireMetricsToken(promhttp.Handler()))
+	mux.Handle("/status", requireMetricsToken(http.HandlerFunc(handleStatus)))
+	mux.HandleFunc("/readyz", handleReadyz)
+	mux.Handle("/fund", requireMetricsToken(fundHandler(client)))
+	server := &http.Server{
+		Addr:              cfg.ServingPort,
+		Handler:           mux,
+		ReadTimeout:       metricsReadTimeout,
+		ReadHeaderTimeout: metricsReadTimeout,
+		WriteTimeout:      metricsWriteTimeout,
+		IdleTimeout:       metricsIdleTimeout,
+	}
+	go func() {
+		log.Printf("Prometheus metrics server running on port %s", cfg.ServingPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Error starting Prometheus HTTP server: %v", err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("Error shutting down HTTP server:", err)
+		}
+	}()
+
+	validatorAddress, err := startupWithRetry(ctx, client)
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("startup failed: %w", err)
+	}
+	log.Println("Validator address:", validatorAddress)
+	fundTarget.Store(balanceGateAddress(validatorAddress))
+	startupComplete.Store(true)
+	if err := checkAddressKeyFile(validatorAddress); err != nil {
+		if activationEnabled {
+			return fmt.Errorf("key mismatch: %w", err)
+		}
+		log.Printf("Key mismatch: %v. Continuing in monitor-only mode.", err)
+	}
+	prometheus.ValidatorActivatedGauge.WithLabelValues(validatorAddress).Set(0)
+	prometheus.ValidatorUnlockExpiresInGauge.WithLabelValues(validatorAddress).Set(0) // Locked until the first unlock
+	prometheus.ValidatorActivatedCounterGauge.WithLabelValues(validatorAddress).Set(0)
+	prometheus.ValidatorActivationAttemptsSinceSuccessGauge.WithLabelValues(validatorAddress).Set(0)
+	prometheus.ValidatorJailEscalationGauge.WithLabelValues(validatorAddress).Set(0)
+	loadDeletedState(validatorAddress)
+
+	_, err = client.GetValidatorByAddress(validatorAddress)
+	if err != nil && validatorDeleted.Load() {
+		// A deleted validator is parked: never fund, top up or recreate it
+		log.Printf("Validator %s was deleted, parked without funding or activation. Monitoring only.", validatorAddress)
+		summary.setState("deleted")
+	} else if err != nil {
+		log.Println("Validator not active. Needs activation:", err)
+		sufficientBalance, currentBalance := checkSufficientBalance(client, validatorAddress)
+		if sufficientBalance {
+			log.Printf("Sufficient Balance detected: %.2f NIM. Checking validator status...", currentBalance)
+			checkAndHandleValidatorStatus(ctx, client, validatorAddress)
+		} else {
+			balanceNeeded := minStakeNIM - currentBalance
+			log.Printf("Initial balance insufficient: %.0f NIM needed to reach %.0f NIM.", balanceNeeded, minStakeNIM)
+			if err := periodicUpdates(ctx, client, validatorAddress, cfg.FundingPollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	ticker := time.NewTicker(cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Shutting down.")
+			return nil
+		case <-ticker.C:
+		}
+		beginIterationSummary(client, validatorAddress)
+		updateEpochNumberGauge(client)
+		updateHeadLagGauge(client)
+		updateActiveValidatorCount(client)
+		updateEpochReward(client, validatorAddress)
+		updateStakerMetrics(client, validatorAddress)
+		state := checkAndHandleValidatorStatus(ctx, client, validatorAddress)
+		if !state {
+			stepf("Something went wrong. with the validator!")
+		}
+		recordIteration(state)
+		if gate := balanceGateAddress(validatorAddress); gate != validatorAddress {
+			updateBalanceMetrics(client, validatorAddress, gate)
+		} else {
+			updateBalanceMetrics(client, validatorAddress)
+		}
+		updateUnlockExpiryGauges()
+		logIterationSummary()
+		if runLimitReached() {
+			return runLimitResult(summary.currentState() == "active")
+		}
+	}
+}
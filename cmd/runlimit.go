@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// runStarted is when the activator started, for MAX_RUNTIME
+	runStarted = time.Now()
+	// loopIterations counts the iterations of the funding and main loops, for MAX_ITERATIONS
+	loopIterations atomic.Int64
+)
+
+// runLimitReached counts a finished loop iteration and reports whether MAX_ITERATIONS or MAX_RUNTIME is hit
+func runLimitReached() bool {
+	iterations := loopIterations.Add(1)
+	if maxIterations > 0 && iterations >= int64(maxIterations) {
+		log.Printf("Reached MAX_ITERATIONS of %d.", maxIterations)
+		return true
+	}
+	if maxRuntime > 0 && time.Since(runStarted) >= maxRuntime {
+		log.Printf("Reached MAX_RUNTIME of %s after %d iterations.", maxRuntime, iterations)
+		return true
+	}
+	return false
+}
+
+// errRunLimitNotActive is returned by Run when MAX_ITERATIONS or MAX_RUNTIME ends the run before the validator is active
+var errRunLimitNotActive = errors.New("run limit reached with the validator not active")
+
+// runLimitResult ends a limited run, successfully only when the validator ended up active
+func runLimitResult(active bool) error {
+	if active {
+		log.Printf("Validator is active. Exiting.")
+		return nil
+	}
+	log.Printf("Validator is not active. Exiting with failure.")
+	return errRunLimitNotActive
+}
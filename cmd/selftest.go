@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"nimiq-validator-activator/rpc"
+	"os"
+	"text/tabwriter"
+)
+
+// selfTestCheck is one read-only RPC call exercised by the selftest subcommand
+type selfTestCheck struct {
+	method   string
+	required bool
+	run      func() error
+}
+
+// runSelfTest calls every read-only RPC method the activator uses against the configured node
+// and prints a pass/fail table. It never calls a mutating method and fails when a required method fails.
+func runSelfTest(client NimiqRPC) error {
+	address := configuredValidatorAddress
+	checks := []selfTestCheck{
+		{"ping", true, client.Ping},
+		{"isConsensusEstablished", true, func() error {
+			_, err := client.IsConsensusEstablished()
+			return err
+		}},
+		{"getBlockNumber", true, func() error {
+			_, err := client.GetCurrentBlockNumber()
+			return err
+		}},
+		{"getEpochNumber", false, func() error {
+			_, err := client.GetEpochNumber()
+			return err
+		}},
+		{"getAddress", address == "", func() error {
+			nodeAddress, err := client.GetAddress()
+			if err == nil && address == "" {
+				address = nodeAddress
+			}
+			return err
+		}},
+		{"listAccounts", false, func() error {
+			_, err := client.ListAccounts()
+			return err
+		}},
+		{"getAccountByAddress", true, func() error {
+			_, err := client.GetAccountBalanceByAddress(address)
+			return err
+		}},
+		{"getAccountBalances (batch)", false, func() error {
+			_, err := client.GetAccountBalances([]string{address})
+			return err
+		}},
+		{"getValidatorByAddress", true, func() error {
+			_, err := client.GetValidatorByAddress(address)
+			if errors.Is(err, rpc.ErrValidatorNotFound) {
+				return nil // Answering for a validator that does not exist yet is fine
+			}
+			return err
+		}},
+		{"getStakersByValidatorAddress", false, func() error {
+			_, err := client.GetTotalStakeByValidatorAddress(address)
+			return err
+		}},
+		{"getParkedValidators", false, func() error {
+			_, err := client.GetParkedValidators()
+			return err
+		}},
+		{"getActiveValidators", false, func() error {
+			_, err := client.GetActiveValidators()
+			return err
+		}},
+		{"getBlockByNumber", false, func() error {
+			head, err := client.GetCurrentBlockNumber()
+			if err != nil {
+				return err
+			}
+			_, err = client.GetBlockByNumber(head)
+			return err
+		}},
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "METHOD\tREQUIRED\tRESULT\tDETAIL")
+	failed := 0
+	for _, check := range checks {
+		result, detail := "PASS", ""
+		if err := check.run(); err != nil {
+			result, detail = "FAIL", err.Error()
+			if check.required {
+				failed++
+			}
+		}
+		fmt.Fprintf(table, "%s\t%t\t%s\t%s\n", check.method, check.required, result, detail)
+	}
+	table.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d required RPC methods failed", failed)
+	}
+	return nil
+}
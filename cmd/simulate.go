@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nimiq-validator-activator/faucet"
+	"nimiq-validator-activator/prometheus"
+	"nimiq-validator-activator/rpc"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// simulatedDeposit is the amount the mock node locks when a validator is created
+const simulatedDeposit = int64(100000 * lunaPerNIM)
+
+// mockNode is an in-memory Nimiq node with scripted state, used by the simulate subcommand
+type mockNode struct {
+	consensus   bool
+	address     string
+	balance     int64
+	validator   *rpc.ValidatorDetails
+	blockNumber int64
+	sent        []string // RPC methods of the mutating transactions received
+}
+
+func (n *mockNode) Ping() error                                 { return nil }
+func (n *mockNode) IsConsensusEstablished() (bool, error)       { return n.consensus, nil }
+func (n *mockNode) GetEpochNumber() (int, error)                { return int(n.blockNumber / 43200), nil }
+func (n *mockNode) GetAddress() (string, error)                 { return n.address, nil }
+func (n *mockNode) GetElectionBlockOf(epoch int) (int64, error) { return int64(epoch) * 43200, nil }
+func (n *mockNode) GetInherentsByBlockNumber(blockNumber int64) ([]rpc.Inherent, error) {
+	return nil, nil
+}
+func (n *mockNode) ListAccounts() ([]string, error) { return []string{n.address}, nil }
+func (n *mockNode) GetParkedValidators() ([]string, error) {
+	return nil, nil
+}
+
+func (n *mockNode) GetActiveValidators() ([]rpc.ValidatorDetails, error) {
+	if n.validator == nil || n.validator.InactivityFlag != nil || n.validator.JailedFrom != nil || n.validator.Retired {
+		return nil, nil
+	}
+	return []rpc.ValidatorDetails{*n.validator}, nil
+}
+
+func (n *mockNode) GetStakerByAddress(address string) (*rpc.StakerDetails, error) {
+	return nil, fmt.Errorf("%w: %s", rpc.ErrStakerNotFound, address)
+}
+
+func (n *mockNode) GetBlockByNumber(blockNumber int64) (*rpc.Block, error) {
+	return &rpc.Block{Number: blockNumber, Timestamp: time.Now().UnixMilli()}, nil
+}
+
+// GetCurrentBlockNumber advances the chain on every call so pre-send checks see a live node
+func (n *mockNode) GetCurrentBlockNumber() (int64, error) {
+	n.blockNumber++
+	return n.blockNumber, nil
+}
+
+func (n *mockNode) GetAccountBalanceByAddress(address string) (int64, error) {
+	return n.balance, nil
+}
+
+func (n *mockNode) GetAccountBalances(addresses []string) (map[string]int64, error) {
+	balances := make(map[string]int64, len(addresses))
+	for _, address := range addresses {
+		balances[address] = n.balance
+	}
+	return balances, nil
+}
+
+func (n *mockNode) GetValidatorByAddress(address string) (*rpc.ValidatorDetails, error) {
+	if n.validator == nil {
+		return nil, fmt.Errorf("%w: %s", rpc.ErrValidatorNotFound, address)
+	}
+	details := *n.validator
+	return &details, nil
+}
+
+// GetTotalStakeByValidatorAddress reports the deposit as the only stake, so stake reconciles
+func (n *mockNode) GetTotalStakeByValidatorAddress(address string) (int64, error) {
+	if n.validator == nil || n.validator.Balance == nil {
+		return 0, nil
+	}
+	return *n.validator.Balance, nil
+}
+
+func (n *mockNode) GetTransactionByHash(hash string) (*rpc.Transaction, error) {
+	return &rpc.Transaction{Hash: hash, BlockNumber: n.blockNumber}, nil
+}
+
+func (n *mockNode) ImportRawKey(privateKey, passphrase string) (string, error) {
+	return n.address, nil
+}
+
+func (n *mockNode) UnlockAccount(address, passphrase string, duration int) error {
+	return nil
+}
+
+func (n *mockNode) SendNewValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error) {
+	n.sent = append(n.sent, "sendNewValidatorTransaction")
+	n.balance -= simulatedDeposit + int64(feeInLuna)
+	deposit := simulatedDeposit
+	n.validator = &rpc.ValidatorDetails{Address: validatorAddress, Balance: &deposit, Deposit: &deposit}
+	return "raw-new-validator-tx", nil
+}
+
+func (n *mockNode) CreateRawValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error) {
+	// The simulation treats a raw validator transaction like one sent through the node wallet
+	if _, err := n.SendNewValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData, feeInLuna, validityStartHeight); err != nil {
+		return "", err
+	}
+	return "unsigned-new-validator-tx", nil
+}
+
+func (n *mockNode) SignTransaction(rawTx, signerAddress string) (string, error) {
+	return "signed-" + rawTx, nil
+}
+
+func (n *mockNode) SendReactivateValidatorTransaction(senderAddress, validatorAddress, signingSecretKey string, feeInLuna int, validityStartHeight string) (string, error) {
+	n.sent = append(n.sent, "sendReactivateValidatorTransaction")
+	n.balance -= int64(feeInLuna)
+	n.validator.Retired = false
+	return "reactivate-tx-hash", nil
+}
+
+func (n *mockNode) SendBasicTransaction(wallet, recipient string, value int64, feeInLuna int, validityStartHeight string) (string, error) {
+	n.sent = append(n.sent, "sendBasicTransaction")
+	n.balance += value
+	return "basic-tx-hash", nil
+}
+
+func (n *mockNode) SendRawTransaction(rawTx string) (string, error) {
+	return rawTx + "-hash", nil
+}
+
+// mockFaucet credits a fixed amount to the mock node on every funding request
+type mockFaucet struct {
+	node   *mockNode
+	amount int64
+}
+
+func (f *mockFaucet) Fund(address string) (faucet.FundResult, error) {
+	f.node.balance += f.amount
+	return faucet.FundResult{StatusCode: 200, Success: true, TxHash: "faucet-tx-hash"}, nil
+}
+
+// metricValue reads the current value of a gauge or counter
+func metricValue(metric promclient.Metric) float64 {
+	var m dto.Metric
+	if err := metric.Write(&m); err != nil {
+		return 0
+	}
+	if m.Gauge != nil {
+		return m.Gauge.GetValue()
+	}
+	return m.Counter.GetValue()
+}
+
+// writeSimulationKeys creates throwaway key files in the formats the activator parses
+func writeSimulationKeys(dir, address string) error {
+	files := map[string]string{
+		"signing_key.txt": "Private Key: simulated-signing-key\n",
+		"vote_key.txt":    "# Public Key:\n\n" + strings.Repeat("ab", 285) + "\n\n# Proof Of Knowledge:\n\n" + strings.Repeat("cd", 95) + "\n\n# Secret Key:\n\n" + strings.Repeat("ef", 95) + "\n",
+		"address.txt":     "Address: " + address + "\nPrivate Key: simulated-address-key\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runSimulation drives the whole validator lifecycle against a mock node and verifies
+// the transactions sent and metrics set at each step:
+// insufficient balance → fund → activate → jailed → retired → reactivate → deleted.
+func runSimulation() error {
+	const address = "NQ07 0000 0000 0000 0000 0000 0000 0000 0000"
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "activator-simulation")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := writeSimulationKeys(dir, address); err != nil {
+		return err
+	}
+
+	// Run fast and with the default lifecycle behavior regardless of the environment
+	keysDir = dir
+	chainAdvanceCheckInterval = 0
+	confirmationTimeout = 0
+	confirmationDepth = 1
+	minStakeNIM = float64(simulatedDeposit) / lunaPerNIM
+	txFeeLuna = 500
+	unlockDurationSeconds = 0
+	activationEnabled = true
+	dryRun = false
+	autoReactivateRetired = true
+	senderAddress = ""
+	rewardAddress = ""
+	jailEscalationThreshold = 0
+	validatorStateFile = ""
+	faucetMinInterval = 0
+	faucetConfirmTimeout = time.Second
+	allowRecreateDeleted = false
+	setLeader(true)
+	if err := checkAddressKeyFile(address); err != nil {
+		return err
+	}
+
+	node := &mockNode{consensus: true, address: address, blockNumber: 1000}
+	faucetClient = &mockFaucet{node: node, amount: int64(60000 * lunaPerNIM)}
+
+	sentCount := 0
+	expectSent := func(method string) error {
+		if len(node.sent) != sentCount+1 || node.sent[sentCount] != method {
+			return fmt.Errorf("expected %s to be sent, transactions so far: %v", method, node.sent)
+		}
+		sentCount++
+		return nil
+	}
+	expectNoneSent := func() error {
+		if len(node.sent) != sentCount {
+			return fmt.Errorf("unexpected transactions sent: %v", node.sent[sentCount:])
+		}
+		return nil
+	}
+	expectMetric := func(name string, metric promclient.Metric, want float64) error {
+		if got := metricValue(metric); got != want {
+			return fmt.Errorf("expected %s to be %v, got %v", name, want, got)
+		}
+		return nil
+	}
+
+	steps := []struct {
+		name string
+		run  func() error
+	}{
+		{"insufficient balance", func() error {
+			if sufficient, _ := checkSufficientBalance(node, address); sufficient {
+				return fmt.Errorf("expected balance to be insufficient")
+			}
+			return expectNoneSent()
+		}},
+		{"fund until sufficient", func() error {
+			for i := 0; i < 2; i++ {
+				if !fundAddress(ctx, node, address) {
+					return fmt.Errorf("funding request %d failed", i+1)
+				}
+			}
+			if sufficient, _ := checkSufficientBalance(node, address); !sufficient {
+				return fmt.Errorf("expected balance to be sufficient after funding")
+			}
+			if err := expectMetric("funding confirmed", prometheus.FaucetFundingConfirmedCounter, 2); err != nil {
+				return err
+			}
+			return expectMetric("balance", prometheus.ValidatorBalanceGauge.WithLabelValues(address), float64(node.balance))
+		}},
+		{"activate", func() error {
+			checkAndHandleValidatorStatus(ctx, node, address)
+			if err := expectSent("sendNewValidatorTransaction"); err != nil {
+				return err
+			}
+			if err := expectMetric("activation attempts since success", prometheus.ValidatorActivationAttemptsSinceSuccessGauge.WithLabelValues(address), 0); err != nil {
+				return err
+			}
+			return expectMetric("activated", prometheus.ValidatorActivatedGauge.WithLabelValues(address), 1)
+		}},
+		{"active", func() error {
+			if !checkAndHandleValidatorStatus(ctx, node, address) {
+				return fmt.Errorf("expected validator to be in good standing")
+			}
+			if err := expectMetric("stake reconciliation diff", prometheus.ValidatorStakeReconciliationDiffGauge.WithLabelValues(address), 0); err != nil {
+				return err
+			}
+			return expectNoneSent()
+		}},
+		{"jailed", func() error {
+			jailedFrom := int(node.blockNumber)
+			node.validator.JailedFrom = &jailedFrom
+			if checkAndHandleValidatorStatus(ctx, node, address) {
+				return fmt.Errorf("expected jailed validator not to be in good standing")
+			}
+			if err := expectMetric("jailed", prometheus.ValidatorJailedGauge.WithLabelValues(address), 1); err != nil {
+				return err
+			}
+			return expectNoneSent()
+		}},
+		{"retired and reactivated", func() error {
+			node.validator.JailedFrom = nil
+			node.validator.Retired = true
+			checkAndHandleValidatorStatus(ctx, node, address)
+			if err := expectSent("sendReactivateValidatorTransaction"); err != nil {
+				return err
+			}
+			if err := expectMetric("reactivations", prometheus.ValidatorReActivatedCounterGauge.WithLabelValues(address), 1); err != nil {
+				return err
+			}
+			return expectMetric("retired reactivations", prometheus.ValidatorReactivationsCounter.WithLabelValues(address, reactivationRetired), 1)
+		}},
+		{"active again", func() error {
+			if !checkAndHandleValidatorStatus(ctx, node, address) {
+				return fmt.Errorf("expected validator to be in good standing")
+			}
+			if err := expectMetric("jailed", prometheus.ValidatorJailedGauge.WithLabelValues(address), 0); err != nil {
+				return err
+			}
+			return expectNoneSent()
+		}},
+		{"deleted and not recreated", func() error {
+			node.validator = nil
+			checkAndHandleValidatorStatus(ctx, node, address)
+			if err := expectMetric("deleted", prometheus.ValidatorDeletedGauge.WithLabelValues(address), 1); err != nil {
+				return err
+			}
+			return expectNoneSent()
+		}},
+	}
+
+	for _, step := range steps {
+		log.Printf("[SIMULATION] %s", step.name)
+		if err := step.run(); err != nil {
+			return fmt.Errorf("step %q: %w", step.name, err)
+		}
+	}
+	return nil
+}
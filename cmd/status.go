@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+var (
+	// canActStatus mirrors nimiq_activator_can_act for the status endpoint
+	canActStatus atomic.Bool
+	// startupComplete is set once the node answered and the validator address was resolved
+	startupComplete atomic.Bool
+)
+
+// statusResponse is the JSON document served on /status
+type statusResponse struct {
+	Version             string       `json:"version"`
+	ValidatorAddress    string       `json:"validator_address"`
+	State               string       `json:"state"`
+	LastAction          string       `json:"last_action"`
+	Epoch               int64        `json:"epoch"`
+	Leader              bool         `json:"leader"`
+	CanAct              bool         `json:"can_act"`
+	ConsecutiveFailures int64        `json:"consecutive_failures"`
+	Config              statusConfig `json:"config"`
+}
+
+// statusConfig is the effective non-secret configuration. Fields are listed explicitly
+// so key material, passwords and full node URLs can never end up in the response.
+type statusConfig struct {
+	Network               string  `json:"network"`
+	NodeHost              string  `json:"node_host"`
+	KeysDir               string  `json:"keys_dir"`
+	MinStakeNIM           float64 `json:"min_stake_nim"`
+	StakeBufferNIM        float64 `json:"stake_buffer_nim"`
+	TxFeeLuna             int     `json:"tx_fee_luna"`
+	MaxFeeLuna            int     `json:"max_fee_luna"`
+	FeeBumpFactor         float64 `json:"fee_bump_factor"`
+	FeeBumpMaxAttempts    int     `json:"fee_bump_max_attempts"`
+	JailReleaseBlocks     int     `json:"jail_release_blocks"`
+	PollInterval          string  `json:"poll_interval"`
+	FundingPollInterval   string  `json:"funding_poll_interval"`
+	ConfirmationTimeout   string  `json:"confirmation_timeout"`
+	TxSigningMode         string  `json:"tx_signing_mode"`
+	ActivationEnabled     bool    `json:"activation_enabled"`
+	DryRun                bool    `json:"dry_run"`
+	AutoReactivateRetired bool    `json:"auto_reactivate_retired"`
+	TopUpEnabled          bool    `json:"topup_enabled"`
+	LeaderElection        bool    `json:"leader_election"`
+}
+
+// nodeHost returns only the host of the node URL, dropping credentials, path and query
+func nodeHost() string {
+	parsed, err := url.Parse(nimiqNodeUrl)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+func effectiveStatusConfig() statusConfig {
+	return statusConfig{
+		Network:               network,
+		NodeHost:              nodeHost(),
+		KeysDir:               keysDir,
+		MinStakeNIM:           minStakeNIM,
+		StakeBufferNIM:        stakeBufferNIM,
+		TxFeeLuna:             txFeeLuna,
+		MaxFeeLuna:            maxFeeLuna,
+		FeeBumpFactor:         feeBumpFactor,
+		FeeBumpMaxAttempts:    feeBumpMaxAttempts,
+		JailReleaseBlocks:     jailReleaseBlocks,
+		PollInterval:          pollInterval.String(),
+		FundingPollInterval:   fundingPollInterval.String(),
+		ConfirmationTimeout:   confirmationTimeout.String(),
+		TxSigningMode:         txSigningMode,
+		ActivationEnabled:     activationEnabled,
+		DryRun:                dryRun,
+		AutoReactivateRetired: autoReactivateRetired,
+		TopUpEnabled:          topUpEnabled,
+		LeaderElection:        leaderLeaseFile != "",
+	}
+}
+
+// handleStatus serves the runtime state of the activator together with its effective configuration
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	summary.mu.Lock()
+	status := statusResponse{
+		Version:          appVersion,
+		ValidatorAddress: summary.address,
+		State:            summary.lastState,
+		LastAction:       summary.action,
+	}
+	summary.mu.Unlock()
+	if status.State == "" {
+		status.State = "starting" // No main loop iteration finished yet
+	}
+	status.Epoch = currentEpoch.Load()
+	status.Leader = isLeader()
+	status.CanAct = canActStatus.Load()
+	status.ConsecutiveFailures = consecutiveFailures.Load()
+	status.Config = effectiveStatusConfig()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Println("Error writing status response:", err)
+	}
+}
+
+// handleReadyz reports readiness once startup completed and, with READY_REQUIRES_ACTIVE,
+// only once the validator is confirmed active so orchestration can wait for it
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !startupComplete.Load() {
+		http.Error(w, "not ready: starting", http.StatusServiceUnavailable)
+		return
+	}
+	state := summary.lastKnownState()
+	if readyRequiresActive && state != "active" {
+		http.Error(w, fmt.Sprintf("not ready: validator is %s", state), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ready")
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"nimiq-validator-activator/logging"
+	"sync"
+)
+
+// iterationSummary collects what a main loop iteration observed and did for the SUMMARY_LOG line
+type iterationSummary struct {
+	mu          sync.Mutex
+	address     string
+	consensus   string
+	blockNumber int64
+	state       string
+	balance     int64
+	hasBalance  bool
+	action      string
+	lastState   string // Last state determined, kept across iterations for readiness checks
+}
+
+// summary is the summary of the main loop iteration in progress
+var summary iterationSummary
+
+// beginIterationSummary resets the summary at the start of a main loop iteration
+func beginIterationSummary(client NimiqRPC, address string) {
+	consensus := "unknown"
+	if summaryLog {
+		if established, err := client.IsConsensusEstablished(); err == nil {
+			consensus = fmt.Sprint(established)
+		}
+	}
+
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	summary.address = address
+	summary.consensus = consensus
+	summary.blockNumber = 0
+	summary.state = "unknown"
+	summary.balance, summary.hasBalance = 0, false
+	summary.action = "none"
+}
+
+func (s *iterationSummary) setState(state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	s.lastState = state
+}
+
+func (s *iterationSummary) setAction(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.action = action
+}
+
+func (s *iterationSummary) setBlockNumber(blockNumber int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blockNumber = blockNumber
+}
+
+// setBalance records the balance when it belongs to the summarized validator
+func (s *iterationSummary) setBalance(address string, balance int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if address == s.address {
+		s.balance, s.hasBalance = balance, true
+	}
+}
+
+// lastKnownState returns the most recent state determined, unaffected by an iteration in progress
+func (s *iterationSummary) lastKnownState() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastState == "" {
+		return "unknown"
+	}
+	return s.lastState
+}
+
+func (s *iterationSummary) currentState() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// logIterationSummary emits the single summary line of the finished iteration
+func logIterationSummary() {
+	if !summaryLog {
+		return
+	}
+	summary.mu.Lock()
+	defer summary.mu.Unlock()
+	balance := "unknown"
+	if summary.hasBalance {
+		balance = fmt.Sprintf("%.2f", float64(summary.balance)/lunaPerNIM)
+	}
+	log.Printf("summary address=%q consensus=%s block=%d epoch=%d state=%s balance_nim=%s action=%s",
+		summary.address, summary.consensus, summary.blockNumber, currentEpoch.Load(), summary.state, balance, summary.action)
+}
+
+// stepf logs per-step progress, demoted to debug when SUMMARY_LOG condenses each iteration into one line
+func stepf(format string, args ...interface{}) {
+	if summaryLog {
+		logging.Debugf(format, args...)
+		return
+	}
+	log.Printf(format, args...)
+}
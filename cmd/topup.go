@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"nimiq-validator-activator/prometheus"
+	"sync"
+	"time"
+)
+
+// topUp is a transfer sent from the funding account, kept to enforce the per-period cap
+type topUp struct {
+	at     time.Time
+	amount int64
+}
+
+var (
+	recentTopUps []topUp
+	topUpMu      sync.Mutex // Held for a whole top-up so concurrent callers never exceed the cap
+)
+
+// topUpFromFundingAccount transfers enough from the funding account to bring the validator
+// balance back above the minimum stake plus buffer, never exceeding the per-period cap
+func topUpFromFundingAccount(client NimiqRPC, address string, balanceNIM float64) error {
+	if !isLeader() {
+		return errNotLeader
+	}
+	topUpMu.Lock()
+	defer topUpMu.Unlock()
+
+	target := int64((minStakeNIM + stakeBufferNIM) * lunaPerNIM)
+	amount := target - int64(balanceNIM*lunaPerNIM)
+	if amount <= 0 {
+		return nil
+	}
+
+	// Only count top-ups inside the current period towards the cap
+	now := time.Now()
+	var spent int64
+	kept := recentTopUps[:0]
+	for _, t := range recentTopUps {
+		if now.Sub(t.at) < topUpPeriod {
+			kept = append(kept, t)
+			spent += t.amount
+		}
+	}
+	recentTopUps = kept
+
+	remaining := int64(topUpMaxNIMPerPeriod*lunaPerNIM) - spent
+	if remaining <= 0 {
+		return fmt.Errorf("top-up cap of %.0f NIM per %s reached", topUpMaxNIMPerPeriod, topUpPeriod)
+	}
+	if amount > remaining {
+		log.Printf("Top-up of %d Luna capped to the remaining %d Luna for this period.", amount, remaining)
+		amount = remaining
+	}
+
+	if skipForDryRun(dryRunTopUp, "top up %s with %d Luna from funding account %s with fee %d Luna", address, amount, fundingAddress, txFeeLuna) {
+		return nil
+	}
+	if err := importAndUnlock(client, fundingKeyFile, fundingAddress); err != nil {
+		return err
+	}
+	if err := verifyConsensusBeforeSend(client, address); err != nil {
+		return err
+	}
+
+	if err := confirmLeadership(); err != nil {
+		return err
+	}
+	log.Printf("Topping up %s with %d Luna from funding account %s.", address, amount, fundingAddress)
+	txHash, err := client.SendBasicTransaction(fundingAddress, address, amount, txFeeLuna, "+0")
+	if err != nil {
+		return fmt.Errorf("failed to send top-up transaction: %w", err)
+	}
+	log.Printf("Top-up transaction sent successfully. Hash: %s", txHash)
+
+	recentTopUps = append(recentTopUps, topUp{at: now, amount: amount})
+	prometheus.ActivatorTopUpsCounter.WithLabelValues(address).Inc()
+	prometheus.ActivatorFeesSpentCounter.WithLabelValues("topup").Add(float64(txFeeLuna))
+	return nil
+}
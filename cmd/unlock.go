@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"nimiq-validator-activator/prometheus"
+)
+
+var (
+	// unlockedAt holds the time each account was last unlocked by the activator
+	unlockedAt   = map[string]time.Time{}
+	unlockedAtMu sync.Mutex
+)
+
+// recordUnlock notes a successful unlock and refreshes the expiry gauge for the address
+func recordUnlock(address string) {
+	unlockedAtMu.Lock()
+	unlockedAt[address] = time.Now()
+	unlockedAtMu.Unlock()
+	updateUnlockExpiryGauges()
+}
+
+// unlockExpiresIn returns the seconds left on an unlock, 0 once it has lapsed and +Inf when unbounded
+func unlockExpiresIn(unlocked time.Time, now time.Time) float64 {
+	if unlockDurationSeconds <= 0 {
+		return math.Inf(1) // The node keeps the account unlocked until restart
+	}
+	expires := unlocked.Add(time.Duration(unlockDurationSeconds) * time.Second)
+	if !now.Before(expires) {
+		return 0
+	}
+	return expires.Sub(now).Seconds()
+}
+
+// updateUnlockExpiryGauges recomputes the time left on every unlock the activator performed
+func updateUnlockExpiryGauges() {
+	unlockedAtMu.Lock()
+	defer unlockedAtMu.Unlock()
+	now := time.Now()
+	for address, unlocked := range unlockedAt {
+		prometheus.ValidatorUnlockExpiresInGauge.WithLabelValues(address).Set(unlockExpiresIn(unlocked, now))
+	}
+}
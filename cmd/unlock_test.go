@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestUnlockExpiresIn(t *testing.T) {
+	unlocked := time.Now()
+	tests := []struct {
+		name     string
+		duration int
+		now      time.Time
+		want     float64
+	}{
+		{"unbounded", 0, unlocked.Add(time.Hour), math.Inf(1)},
+		{"remaining", 600, unlocked.Add(4 * time.Minute), 360},
+		{"lapsed", 600, unlocked.Add(11 * time.Minute), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unlockDurationSeconds = test.duration
+			defer func() { unlockDurationSeconds = 0 }()
+			if got := unlockExpiresIn(unlocked, test.now); got != test.want {
+				t.Errorf("unlockExpiresIn = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
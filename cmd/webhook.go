@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"nimiq-validator-activator/prometheus"
+	"time"
+)
+
+// Lifecycle events sent to WEBHOOK_URL
+const (
+	webhookActivation   = "activation"
+	webhookReactivation = "reactivation"
+	webhookJailing      = "jailing"
+	webhookRetirement   = "retirement"
+)
+
+// webhookEvent is the JSON body posted to WEBHOOK_URL
+type webhookEvent struct {
+	Event       string `json:"event"`
+	Address     string `json:"address"`
+	TxHash      string `json:"tx_hash,omitempty"`
+	BlockNumber int64  `json:"block_number,omitempty"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// webhookQueue buffers events for the delivery worker, nil while no webhook is configured
+var webhookQueue chan webhookEvent
+
+// startWebhookNotifier starts delivering lifecycle events to WEBHOOK_URL in the background
+func startWebhookNotifier() {
+	if webhookURL == "" {
+		return
+	}
+	webhookQueue = make(chan webhookEvent, 32)
+	client := &http.Client{Timeout: webhookTimeout}
+	go func() {
+		for event := range webhookQueue {
+			status := "success"
+			if err := deliverWebhook(client, event); err != nil {
+				log.Printf("Webhook delivery of %s event failed: %v", event.Event, err)
+				status = "failure"
+			}
+			prometheus.WebhookDeliveriesCounter.WithLabelValues(status).Inc()
+		}
+	}()
+}
+
+// notifyWebhook queues a lifecycle event for delivery without ever blocking the caller.
+// The block number is the node's head when the event was seen.
+func notifyWebhook(client NimiqRPC, event, address, txHash string) {
+	if webhookQueue == nil {
+		return
+	}
+	blockNumber, err := client.GetCurrentBlockNumber()
+	if err != nil {
+		log.Println("Error fetching current block number for webhook:", err)
+	}
+	select {
+	case webhookQueue <- webhookEvent{Event: event, Address: address, TxHash: txHash, BlockNumber: blockNumber, Timestamp: time.Now().Unix()}:
+	default:
+		log.Printf("Webhook queue full, dropping %s event.", event)
+		prometheus.WebhookDeliveriesCounter.WithLabelValues("dropped").Inc()
+	}
+}
+
+func deliverWebhook(client *http.Client, event webhookEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned non-OK status: %d %s", resp.StatusCode, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package faucet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds a whole faucet request, so a hung faucet cannot block funding indefinitely
+const DefaultTimeout = 30 * time.Second
+
+// ErrTokenFetch is returned when the anti-abuse token could not be fetched, before the faucet itself was contacted
+var ErrTokenFetch = errors.New("faucet token fetch failed")
+
+// FundResult holds the outcome of a funding request
+type FundResult struct {
+	StatusCode int
+	Success    bool
+	Message    string
+	MaxAmount  int64  // Largest amount the faucet accepts, when it reports one
+	TxHash     string // Hash of the funding transaction, when the faucet reports it
+}
+
+// Faucet requests funds for an address
+type Faucet interface {
+	Fund(address string) (FundResult, error)
+}
+
+// HTTPFaucet posts funding requests as URL-encoded form data to a faucet endpoint
+type HTTPFaucet struct {
+	URL         string
+	UserAgent   string // Sent with every request when set
+	TokenURL    string // Challenge endpoint the anti-abuse token is fetched from before funding, when set
+	TokenHeader string // Header the token is sent in
+	Amount      int64  // Amount in Luna requested per funding request, omitted when 0
+	HTTPClient  *http.Client
+
+	mu sync.Mutex // Guards Amount, which Fund lowers to the faucet's maximum while requests may run concurrently
+}
+
+// NewHTTPFaucet creates a faucet client for the given endpoint
+func NewHTTPFaucet(faucetURL string) *HTTPFaucet {
+	return &HTTPFaucet{
+		URL:         faucetURL,
+		TokenHeader: "X-Faucet-Token",
+		HTTPClient:  &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Fund asks the faucet to send funds to the given address. When the faucet rejects the
+// configured amount and reports its maximum, the request is repeated once with that maximum,
+// which is then used for all further requests.
+func (f *HTTPFaucet) Fund(address string) (FundResult, error) {
+	f.mu.Lock()
+	amount := f.Amount
+	f.mu.Unlock()
+
+	result, err := f.fund(address, amount)
+	if !result.Success && result.MaxAmount > 0 && amount > result.MaxAmount {
+		f.mu.Lock()
+		f.Amount = min(f.Amount, result.MaxAmount)
+		f.mu.Unlock()
+		return f.fund(address, result.MaxAmount)
+	}
+	return result, err
+}
+
+func (f *HTTPFaucet) fund(address string, amount int64) (FundResult, error) {
+	// Preparing data as URL-encoded form data
+	data := url.Values{}
+	data.Set("address", address)
+	if amount > 0 {
+		data.Set("amount", strconv.FormatInt(amount, 10))
+	}
+
+	req, err := http.NewRequest(http.MethodPost, f.URL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return FundResult{}, fmt.Errorf("error building faucet request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	if f.TokenURL != "" {
+		token, err := f.fetchToken()
+		if err != nil {
+			return FundResult{}, fmt.Errorf("%w: %w", ErrTokenFetch, err)
+		}
+		req.Header.Set(f.TokenHeader, token)
+	}
+
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return FundResult{}, fmt.Errorf("error posting to faucet: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := parseResponse(resp)
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("faucet returned non-OK status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	return result, nil
+}
+
+// fetchToken gets a fresh anti-abuse token from the challenge endpoint. The endpoint may answer
+// with JSON like {"token": "..."} or with the bare token as plain text.
+func (f *HTTPFaucet) fetchToken() (string, error) {
+	req, err := http.NewRequest(http.MethodGet, f.TokenURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("error building faucet token request: %w", err)
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+
+	resp, err := f.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error fetching faucet token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("faucet token endpoint returned non-OK status: %d %s", resp.StatusCode, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("error reading faucet token: %w", err)
+	}
+	var payload struct {
+		Token string `json:"token"`
+	}
+	token := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &payload); err == nil {
+		token = payload.Token
+	}
+	if token == "" {
+		return "", fmt.Errorf("faucet token endpoint returned no token")
+	}
+	return token, nil
+}
+
+// parseResponse reads the faucet reply. Faucets answering with a JSON body like
+// {"success": false, "msg": "..."} are decoded, anything else is judged by status code.
+func parseResponse(resp *http.Response) FundResult {
+	result := FundResult{
+		StatusCode: resp.StatusCode,
+		Success:    resp.StatusCode == http.StatusOK,
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return result
+	}
+
+	var payload struct {
+		Success   *bool  `json:"success"`
+		Msg       string `json:"msg"`
+		Message   string `json:"message"`
+		MaxAmount int64  `json:"max_amount"`
+		Hash      string `json:"hash"`
+		TxHash    string `json:"txHash"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		result.Message = string(body)
+		return result
+	}
+
+	if payload.Success != nil {
+		result.Success = result.Success && *payload.Success
+	}
+	result.MaxAmount = payload.MaxAmount
+	result.TxHash = payload.TxHash
+	if result.TxHash == "" {
+		result.TxHash = payload.Hash
+	}
+	result.Message = payload.Msg
+	if result.Message == "" {
+		result.Message = payload.Message
+	}
+	return result
+}
@@ -0,0 +1,150 @@
+package faucet
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFund(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantErr     bool
+		wantSuccess bool
+		wantMessage string
+		wantTxHash  string
+	}{
+		{"json success", http.StatusOK, `{"success":true,"msg":"sent","txHash":"abc"}`, false, true, "sent", "abc"},
+		{"json hash field", http.StatusOK, `{"success":true,"hash":"def"}`, false, true, "", "def"},
+		{"json rejection", http.StatusOK, `{"success":false,"message":"address already funded"}`, false, false, "address already funded", ""},
+		{"plain text", http.StatusOK, `ok`, false, true, "ok", ""},
+		{"server error", http.StatusInternalServerError, `internal error`, true, false, "internal error", ""},
+		{"rate limited", http.StatusTooManyRequests, `{"msg":"slow down"}`, true, false, "slow down", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil || r.PostForm.Get("address") != "NQ07" {
+					t.Errorf("unexpected form %v (%v)", r.PostForm, err)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			result, err := NewHTTPFaucet(server.URL).Fund("NQ07")
+			if (err != nil) != test.wantErr {
+				t.Fatalf("Fund error = %v, want error %t", err, test.wantErr)
+			}
+			if result.StatusCode != test.status || result.Success != test.wantSuccess || result.Message != test.wantMessage || result.TxHash != test.wantTxHash {
+				t.Errorf("Fund = %+v", result)
+			}
+		})
+	}
+}
+
+// maxAmountServer rejects requests above maxAmount, reporting the maximum it accepts
+func maxAmountServer(maxAmount int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		amount, _ := strconv.ParseInt(r.FormValue("amount"), 10, 64)
+		if amount > maxAmount {
+			w.Write([]byte(`{"success":false,"msg":"amount too high","max_amount":` + strconv.FormatInt(maxAmount, 10) + `}`))
+			return
+		}
+		w.Write([]byte(`{"success":true}`))
+	}))
+}
+
+func TestFundLowersAmountToMaximum(t *testing.T) {
+	server := maxAmountServer(1000)
+	defer server.Close()
+
+	f := NewHTTPFaucet(server.URL)
+	f.Amount = 5000
+	result, err := f.Fund("NQ07")
+	if err != nil || !result.Success {
+		t.Fatalf("Fund = %+v, %v, want success after retrying with the maximum", result, err)
+	}
+	if f.Amount != 1000 {
+		t.Errorf("Amount = %d, want 1000", f.Amount)
+	}
+}
+
+// TestFundConcurrent runs funding requests from several goroutines, run with -race
+func TestFundConcurrent(t *testing.T) {
+	server := maxAmountServer(1000)
+	defer server.Close()
+
+	f := NewHTTPFaucet(server.URL)
+	f.Amount = 5000
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if result, err := f.Fund("NQ07"); err != nil || !result.Success {
+				t.Errorf("Fund = %+v, %v", result, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFundTokenFetchFailure(t *testing.T) {
+	funded := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		funded = true
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		tokenURL string
+	}{
+		{"token endpoint error", server.URL + "/token"},
+		{"token endpoint unreachable", "http://127.0.0.1:1/token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := NewHTTPFaucet(server.URL + "/tapit")
+			f.TokenURL = test.tokenURL
+			if _, err := f.Fund("NQ07"); !errors.Is(err, ErrTokenFetch) {
+				t.Fatalf("Fund error = %v, want ErrTokenFetch", err)
+			}
+			if funded {
+				t.Error("faucet contacted without a token")
+			}
+		})
+	}
+}
+
+// TestFundTimeout checks that a hung faucet fails the request instead of blocking it
+func TestFundTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	f := NewHTTPFaucet(server.URL)
+	f.HTTPClient.Timeout = 20 * time.Millisecond
+	started := time.Now()
+	if _, err := f.Fund("NQ07"); err == nil {
+		t.Fatal("expected an error from a hung faucet")
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Fund returned after %s, want the client timeout", elapsed)
+	}
+}
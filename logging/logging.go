@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// debugEnabled is set when LOG_LEVEL=debug
+var debugEnabled = strings.EqualFold(os.Getenv("LOG_LEVEL"), "debug")
+
+// DebugEnabled reports whether debug logging is active
+func DebugEnabled() bool {
+	return debugEnabled
+}
+
+// SetDebug turns debug logging on or off
+func SetDebug(enabled bool) {
+	debugEnabled = enabled
+}
+
+// Debugf logs a message only when debug logging is active
+func Debugf(format string, args ...interface{}) {
+	if debugEnabled {
+		log.Printf("[DEBUG] "+format, args...)
+	}
+}
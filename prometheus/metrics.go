@@ -5,11 +5,67 @@ import (
 )
 
 var (
+	// ActivatorConfigGauge exposes the effective numeric configuration of the activator
+	ActivatorConfigGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_activator_config",
+		Help: "Effective numeric configuration of the activator, labeled by setting name.",
+	}, []string{"name"})
+
+	ActivatorKeyFilePresentGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_activator_key_file_present",
+		Help: "Whether a key file exists and is readable at startup, 1 for yes, 0 for no.",
+	}, []string{"file"}) // Label by key file: signing, vote or address
+
+	ActivatorAddressInvalidGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_activator_address_invalid",
+		Help: "Whether an address learned at runtime is malformed, 1 for yes, 0 for no.",
+	}, []string{"role"}) // Label by address role, e.g. validator
+
+	ActivatorExpectedNetworkMatchGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_activator_expected_network_match",
+		Help: "Whether NIMIQ_NETWORK matches EXPECTED_NETWORK, 1 for yes, 0 for no. The node's own network is not checked.",
+	})
+
+	ActivatorKeyFileInsecureGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_activator_key_file_insecure",
+		Help: "Whether a key file is accessible by users other than its owner, 1 for yes, 0 for no.",
+	}, []string{"file"}) // Label by key file name
+
+	ActivatorKeysDirInfoGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_activator_keys_dir_info",
+		Help: "Resolved keys directory of the activator, always 1.",
+	}, []string{"dir"})
+
 	// NimiqEpochNumberGauge tracks the current Nimiq epoch number
 	NimiqEpochNumberGauge = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "nimiq_epoch_number",
 		Help: "Current Nimiq epoch number.",
 	})
+	// NimiqHeadTimestampLagGauge tracks how far the node's head block trails the wall clock
+	NimiqHeadTimestampLagGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_node_head_timestamp_lag_seconds",
+		Help: "Seconds between now and the timestamp of the node's head block.",
+	})
+	// NimiqChainAdvancingGauge tracks whether the node's block height advanced before the last send
+	NimiqChainAdvancingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_node_chain_advancing",
+		Help: "Whether the node's block height advanced during the pre-send check, 1 for yes, 0 for no.",
+	})
+	// ActivatorConsecutiveFailuresGauge tracks main loop iterations in a row that failed their checks
+	ActivatorConsecutiveFailuresGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_activator_consecutive_failures",
+		Help: "Number of consecutive main loop iterations that failed, 0 after a successful one.",
+	})
+	// ActivatorCanActGauge tracks whether this instance has its keys and is allowed to activate
+	ActivatorCanActGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_activator_can_act",
+		Help: "Whether the activator has its key files and activation enabled, 1 for yes, 0 for monitor-only.",
+	})
+	// ActivatorIsLeaderGauge tracks whether this replica holds the leader lease
+	ActivatorIsLeaderGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_activator_is_leader",
+		Help: "Whether this activator replica is the leader allowed to send transactions, 1 for yes, 0 for no.",
+	})
 	NimiqValidatorBalanceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "nimiq_validator_balance_luna",
 		Help: "Current balance of the validator in Luna.",
@@ -25,6 +81,67 @@ var (
 		Help: "Balance of the validator in Luna.",
 	}, []string{"address"})
 
+	ValidatorUnlockExpiresInGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_unlock_expires_in_seconds",
+		Help: "Seconds until the account unlock expires, 0 when locked and +Inf for an unbounded unlock.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorInsufficientForFeeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_insufficient_for_fee",
+		Help: "Whether the last activation was skipped because the balance did not cover deposit plus fee, 1 for yes, 0 for no.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorSecondsToThresholdGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_estimated_seconds_to_threshold",
+		Help: "Estimated seconds until the balance covers deposit plus fee from its recent growth, 0 once covered and -1 when not growing.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorDeletedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_deleted",
+		Help: "Whether the validator was deleted and will not be recreated until re-enabled, 1 for yes, 0 for no.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorDepositGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_deposit_luna",
+		Help: "Deposit locked by the validator itself, in Luna. Only set when the node reports the deposit.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorDelegatedStakeGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_delegated_stake_luna",
+		Help: "Stake delegated to the validator by stakers, its balance minus its deposit, in Luna. Only set when the node reports the deposit.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorStakeReconciliationDiffGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_stake_reconciliation_diff_luna",
+		Help: "Node-reported validator balance minus the summed balances of its stakers, in Luna.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorJailBlocksRemainingGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_jail_blocks_remaining",
+		Help: "Blocks until the validator's jail period ends and reactivation becomes possible, 0 when not jailed.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorJailedDurationBlocksGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_jailed_duration_blocks",
+		Help: "Blocks since the validator was jailed, 0 when not jailed.",
+	}, []string{"address"}) // Label for address
+
+	ValidatorJailRecoverySeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nimiq_validator_jail_recovery_seconds",
+		Help:    "Wall-clock time from first detecting a jailing until the validator is seen in good standing again.",
+		Buckets: prometheus.ExponentialBuckets(600, 2, 10),
+	}, []string{"address"})
+
+	ValidatorBalanceEMAGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_balance_ema_luna",
+		Help: "Exponential moving average of the validator balance in Luna.",
+	}, []string{"address"})
+
+	ValidatorEpochRewardGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_epoch_reward_luna",
+		Help: "Rewards in Luna credited to the reward address in the last finished epoch.",
+	}, []string{"address"})
+
 	ValidatorNumStakersGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "nimiq_validator_num_stakers",
 		Help: "Number of stakers for the validator.",
@@ -32,7 +149,17 @@ var (
 
 	ValidatorInactivityFlagGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "nimiq_validator_inactivity_flag",
-		Help: "Inactivity flag for the validator, 0 if active.",
+		Help: "Raw inactivityFlag reported by the node, the block the validator is inactive from, 0 if active. Kept for existing dashboards, prefer nimiq_validator_inactive_from_block.",
+	}, []string{"address"})
+
+	ValidatorInactiveFromBlockGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_inactive_from_block",
+		Help: "Block height the validator is inactive from, 0 if active.",
+	}, []string{"address"})
+
+	ValidatorInactiveGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_inactive",
+		Help: "Whether the validator is inactive, 1 for yes, 0 for no.",
 	}, []string{"address"})
 
 	ValidatorRetiredGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
@@ -50,11 +177,56 @@ var (
 		Help: "Block number from which the validator is jailed, 0 if not jailed.",
 	}, []string{"address"})
 
+	ValidatorJailEscalationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_jail_escalation",
+		Help: "Whether repeated jailing disabled automatic reactivation of a retired validator, 1 for yes, 0 for no.",
+	}, []string{"address"})
+
+	ActiveValidatorCountGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_active_validator_count",
+		Help: "Number of validators in the current active set.",
+	})
+
+	StakerBalanceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_staker_balance_luna",
+		Help: "Active stake of the staker address in Luna.",
+	}, []string{"address"})
+
+	StakerInactiveBalanceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_staker_inactive_balance_luna",
+		Help: "Stake of the staker address being deactivated, in Luna.",
+	}, []string{"address"})
+
+	StakerRetiredBalanceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_staker_retired_balance_luna",
+		Help: "Retired stake of the staker address that can be withdrawn, in Luna.",
+	}, []string{"address"})
+
+	StakerDelegatesToValidatorGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_staker_delegates_to_validator",
+		Help: "Whether the staker address delegates to the monitored validator, 1 for yes, 0 for no.",
+	}, []string{"address"})
+
+	ValidatorParkedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_parked",
+		Help: "Whether the validator is in the parked (disabled) set, 1 for yes, 0 for no.",
+	}, []string{"address"})
+
+	ValidatorActivationNeededGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_activation_needed",
+		Help: "Whether the validator needs an activation or reactivation, 1 for yes, 0 for no.",
+	}, []string{"address"})
+
 	ValidatorActivatedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "nimiq_validator_activated",
 		Help: "Activation status of a Nimiq validator. 1 indicates activated.",
 	}, []string{"address"}) // Label by validator address
 
+	ValidatorActivationAttemptsSinceSuccessGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_validator_activation_attempts_since_success",
+		Help: "Activation attempts since the last confirmed activation, a climbing value means activation keeps failing.",
+	}, []string{"address"}) // Label by validator address
+
 	ValidatorActivatedCounterGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "nimiq_validator_activated_counter",
 		Help: "Activation status of a Nimiq validator.",
@@ -64,22 +236,177 @@ var (
 		Name: "nimiq_validator_reactivated_counter",
 		Help: "Reactivation status of a Nimiq validator.",
 	}, []string{"address"}) // Label by validator address
+
+	ValidatorReactivationsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_validator_reactivations_total",
+		Help: "Reactivation transactions sent for a Nimiq validator, by cause, currently only retired.",
+	}, []string{"address", "cause"})
+
+	ActivatorFeesSpentCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_activator_fees_spent_luna_total",
+		Help: "Total fees in Luna spent on transactions sent by the activator.",
+	}, []string{"type"}) // Label by transaction type
+
+	ActivatorTopUpsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_activator_topups_total",
+		Help: "Top-up transactions sent from the funding account to the validator.",
+	}, []string{"address"})
+
+	RPCLastErrorGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_rpc_last_error",
+		Help: "Set to 1 with the category of the last failure of an RPC method, absent once it succeeds again.",
+	}, []string{"method", "category"})
+
+	FaucetEnabledGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_faucet_enabled",
+		Help: "Whether the network and configuration allow automatic faucet funding, 1 for yes, 0 for no.",
+	})
+
+	FaucetEligibleGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_faucet_eligible",
+		Help: "Whether faucet funding would currently be requested: enabled, balance below the threshold and not in cooldown, 1 for yes, 0 for no.",
+	})
+
+	ValidatorUnexpectedLockCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nimiq_validator_unexpected_lock_total",
+		Help: "Number of sends that failed because the account was locked after we unlocked it and were retried after unlocking again.",
+	})
+
+	ActivatorDryRunSkippedCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_activator_dryrun_skipped_total",
+		Help: "Mutating actions skipped because DRY_RUN is enabled, by action.",
+	}, []string{"action"})
+
+	WebhookDeliveriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_webhook_deliveries_total",
+		Help: "Lifecycle event webhook deliveries, by status: success, failure or dropped.",
+	}, []string{"status"})
+
+	FaucetFailuresCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_faucet_failures_total",
+		Help: "Failed faucet funding requests, by reason: unreachable, auth_failed, http_error or rejected.",
+	}, []string{"reason"})
+
+	FaucetUnreachableGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_faucet_unreachable",
+		Help: "Whether the faucet could not be reached in several consecutive requests, 1 for yes, 0 for no.",
+	})
+
+	FaucetFundingConfirmedCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "nimiq_faucet_funding_confirmed_total",
+		Help: "Number of faucet funding transactions confirmed on chain.",
+	})
+
+	RPCInflightGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nimiq_rpc_inflight",
+		Help: "Number of RPC requests currently in flight to the node.",
+	})
+
+	RPCWaitSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "nimiq_rpc_wait_seconds",
+		Help:    "Time RPC requests waited for a free slot under the in-flight limit.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+	})
+
+	RPCRetriesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_rpc_retries_total",
+		Help: "Read-only RPC calls repeated after a transient transport failure, by method.",
+	}, []string{"method"})
+
+	RPCEndpointHealthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_rpc_endpoint_healthy",
+		Help: "Whether the last request to the RPC endpoint got a JSON-RPC response, 1 for yes, 0 for no.",
+	}, []string{"endpoint"})
+
+	RPCEndpointLastSuccessGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nimiq_rpc_endpoint_last_success_timestamp",
+		Help: "Unix timestamp of the last JSON-RPC response received from the RPC endpoint.",
+	}, []string{"endpoint"})
+
+	ValidatorActivationRaceCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_validator_activation_already_active_total",
+		Help: "Activations skipped because the validator already existed right before sending.",
+	}, []string{"address"})
+
+	ValidatorTxFeeBumpsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_validator_tx_fee_bumps_total",
+		Help: "Activation transactions resent with a higher fee after a confirmation timeout.",
+	}, []string{"address"})
+
+	ValidatorTxConsensusAbortCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nimiq_validator_tx_consensus_aborts_total",
+		Help: "Transactions aborted because consensus was lost right before sending.",
+	}, []string{"address"})
 )
 
 func init() {
 	// Register the new gauges
 	prometheus.MustRegister(
+		ActivatorConfigGauge,
+		ActivatorKeyFilePresentGauge,
+		ActivatorKeysDirInfoGauge,
 		NimiqEpochNumberGauge,
+		NimiqChainAdvancingGauge,
+		NimiqHeadTimestampLagGauge,
+		ActivatorCanActGauge,
+		ActivatorAddressInvalidGauge,
+		ActivatorExpectedNetworkMatchGauge,
+		ActivatorKeyFileInsecureGauge,
+		ActivatorConsecutiveFailuresGauge,
+		ActivatorIsLeaderGauge,
 		NimiqValidatorBalanceGauge,
 		NimiqTotalStakeGauge,
 		ValidatorBalanceGauge,
+		ValidatorBalanceEMAGauge,
+		ValidatorEpochRewardGauge,
 		ValidatorNumStakersGauge,
 		ValidatorInactivityFlagGauge,
+		ValidatorInactiveFromBlockGauge,
+		ValidatorInactiveGauge,
+		ValidatorDepositGauge,
+		ValidatorDelegatedStakeGauge,
 		ValidatorRetiredGauge,
 		ValidatorJailedGauge,
 		ValidatorJailedFromGauge,
+		ValidatorJailEscalationGauge,
+		ValidatorParkedGauge,
+		ActiveValidatorCountGauge,
+		StakerBalanceGauge,
+		StakerInactiveBalanceGauge,
+		StakerRetiredBalanceGauge,
+		StakerDelegatesToValidatorGauge,
+		ValidatorActivationNeededGauge,
 		ValidatorActivatedGauge,
 		ValidatorActivatedCounterGauge,
+		ValidatorActivationAttemptsSinceSuccessGauge,
 		ValidatorReActivatedCounterGauge,
+		ValidatorReactivationsCounter,
+		ActivatorFeesSpentCounter,
+		ActivatorTopUpsCounter,
+		RPCLastErrorGauge,
+		RPCEndpointHealthyGauge,
+		RPCInflightGauge,
+		FaucetFundingConfirmedCounter,
+		FaucetFailuresCounter,
+		FaucetUnreachableGauge,
+		WebhookDeliveriesCounter,
+		ActivatorDryRunSkippedCounter,
+		ValidatorUnexpectedLockCounter,
+		FaucetEnabledGauge,
+		FaucetEligibleGauge,
+		RPCWaitSeconds,
+		RPCEndpointLastSuccessGauge,
+		RPCRetriesCounter,
+		ValidatorActivationRaceCounter,
+		ValidatorTxFeeBumpsCounter,
+		ValidatorTxConsensusAbortCounter,
+		ValidatorUnlockExpiresInGauge,
+		ValidatorInsufficientForFeeGauge,
+		ValidatorSecondsToThresholdGauge,
+		ValidatorDeletedGauge,
+		ValidatorStakeReconciliationDiffGauge,
+		ValidatorJailBlocksRemainingGauge,
+		ValidatorJailedDurationBlocksGauge,
+		ValidatorJailRecoverySeconds,
 	)
 }
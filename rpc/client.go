@@ -2,56 +2,653 @@ package rpc
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"nimiq-validator-activator/logging"
+	"nimiq-validator-activator/prometheus"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// methodNotFoundCode is the JSON-RPC error code returned for unknown methods
+const methodNotFoundCode = -32601
+
+// RPCError is the error object of a JSON-RPC response
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	if len(e.Data) > 0 && string(e.Data) != "null" {
+		return fmt.Sprintf("RPC error %d: %s: %s", e.Code, e.Message, e.Data)
+	}
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// newRPCError decodes a JSON-RPC error object, keeping the raw value as message when it is not an object
+func newRPCError(raw json.RawMessage) *RPCError {
+	var rpcErr RPCError
+	if err := json.Unmarshal(raw, &rpcErr); err != nil || (rpcErr.Code == 0 && rpcErr.Message == "") {
+		return &RPCError{Message: string(raw)}
+	}
+	return &rpcErr
+}
+
+// IsMethodNotFound reports whether err means the node does not support the called RPC method
+func IsMethodNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == methodNotFoundCode {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "method not found")
+}
+
+// IsAccountNotFound reports whether err means the account is missing from the node wallet,
+// e.g. because the node restarted and lost its imported keys
+func IsAccountNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "account not found") || strings.Contains(message, "unknown account")
+}
+
+// IsAccountLocked reports whether err means the account is in the node wallet but locked,
+// e.g. because an operator or another process locked it after we unlocked it
+func IsAccountLocked(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "account locked") || strings.Contains(message, "account is locked")
+}
+
+// ErrInvalidResponse is returned when the endpoint does not answer with a JSON-RPC response
+var ErrInvalidResponse = errors.New("invalid JSON-RPC response")
+
+// ErrEmptyResponse is returned when the node answers with neither a result nor an error, e.g. an
+// empty body or {}. It is transient, the same request usually succeeds when retried.
+var ErrEmptyResponse = errors.New("empty RPC response")
+
+// ErrValidatorNotFound is returned when the node answers getValidatorByAddress with null data
+// or with an RPC error saying the address is not a validator
+var ErrValidatorNotFound = errors.New("validator not found")
+
+// isValidatorNotFoundError reports whether err is the node's RPC error for an address that is not a validator
+func isValidatorNotFoundError(err error) bool {
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	message := strings.ToLower(rpcErr.Message + " " + string(rpcErr.Data))
+	return strings.Contains(message, "validator not found") || strings.Contains(message, "no validator")
+}
+
+// ErrStakerNotFound is returned when the node answers getStakerByAddress with null data,
+// i.e. the address has never staked or withdrew its whole stake
+var ErrStakerNotFound = errors.New("staker not found")
+
+// HTTPStatusError is returned when the endpoint answers with a non-OK HTTP status and no JSON-RPC body
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("node returned HTTP %s", e.Status)
+}
+
 // Client holds the configuration for the Nimiq RPC client
 type Client struct {
-	NodeURL string
+	NodeURL   string
+	UserAgent string        // Sent with every request when set
+	Timeout   time.Duration // Limit for a whole request including reading the response, 0 for none
+	Username  string        // Basic auth credentials for nodes with RPC authentication, unused when empty
+	Password  string
+
+	inflight   chan struct{} // Semaphore limiting concurrent requests, nil for no limit
+	socketPath string        // Unix domain socket the node listens on, for unix:// node URLs
+	httpClient *http.Client
 }
 
+// SetMaxInFlight limits how many requests may be in flight at once to protect small nodes, 0 for no limit.
+// It must be called before the client is used.
+func (c *Client) SetMaxInFlight(limit int) {
+	c.inflight = nil
+	if limit > 0 {
+		c.inflight = make(chan struct{}, limit)
+	}
+}
+
+// Transport returns the HTTP transport requests to the node are sent through
+func (c *Client) Transport() http.RoundTripper {
+	if c.httpClient != nil && c.httpClient.Transport != nil {
+		return c.httpClient.Transport
+	}
+	return http.DefaultTransport
+}
+
+// SetTransport sends all requests to the node through transport, e.g. a RecordingTransport
+// wrapping the current Transport. It must be called before the client is used.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	httpClient := &http.Client{}
+	if c.httpClient != nil {
+		*httpClient = *c.httpClient
+	}
+	httpClient.Transport = transport
+	c.httpClient = httpClient
+}
+
+// acquire waits for a free request slot and returns the function releasing it
+func (c *Client) acquire() func() {
+	if c.inflight == nil {
+		return func() {}
+	}
+	start := time.Now()
+	c.inflight <- struct{}{}
+	prometheus.RPCWaitSeconds.Observe(time.Since(start).Seconds())
+	prometheus.RPCInflightGauge.Inc()
+	return func() {
+		prometheus.RPCInflightGauge.Dec()
+		<-c.inflight
+	}
+}
+
+// Close releases the idle keep-alive connections to the node. The client stays usable and opens
+// new connections for later requests.
+func (c *Client) Close() {
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+}
+
+// Keep-alive settings of the node transport. Connections idle for longer than the poll intervals
+// are kept, so periodic requests reuse them instead of opening a new connection each time.
+const (
+	maxIdleConns    = 8
+	idleConnTimeout = 90 * time.Second
+)
+
+// newTransport returns the transport for requests to the node, with its own pool of keep-alive connections
+func newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = maxIdleConns
+	transport.MaxIdleConnsPerHost = maxIdleConns // All requests go to the same host
+	transport.IdleConnTimeout = idleConnTimeout
+	return transport
+}
+
+// defaultTimeout bounds requests when NIMIQ_RPC_TIMEOUT is unset, so a hanging node cannot wedge the activator
+const defaultTimeout = 30 * time.Second
+
 // NewClient now fetches the Nimiq node URL from an environment variable
 func NewClient() *Client {
 	nodeURL := os.Getenv("NIMIQ_NODE_URL") // Get the Nimiq node URL from an environment variable
 	if nodeURL == "" {
 		nodeURL = "http://node:8648" // Default to testnet if not specified
 	}
+	timeout := defaultTimeout
+	if value := os.Getenv("NIMIQ_RPC_TIMEOUT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			log.Printf("Invalid NIMIQ_RPC_TIMEOUT %q, using %s", value, defaultTimeout)
+		} else {
+			timeout = parsed
+		}
+	}
+
+	// Nodes exposing their RPC only on a Unix domain socket are reached through a socket-dialing transport
+	if socketPath, found := strings.CutPrefix(nodeURL, "unix://"); found {
+		transport := newTransport()
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+		return &Client{
+			NodeURL:    nodeURL,
+			Timeout:    timeout,
+			Username:   os.Getenv("NIMIQ_RPC_USER"),
+			Password:   os.Getenv("NIMIQ_RPC_PASSWORD"),
+			socketPath: socketPath,
+			httpClient: &http.Client{Timeout: timeout, Transport: transport},
+		}
+	}
+	// Managed providers may need the endpoint exactly as given, e.g. with a versioned path and trailing slash
+	normalized := nodeURL
+	if verbatim, _ := strconv.ParseBool(os.Getenv("NIMIQ_NODE_URL_VERBATIM")); !verbatim {
+		var err error
+		normalized, err = normalizeNodeURL(nodeURL)
+		if err != nil {
+			log.Printf("Invalid NIMIQ_NODE_URL %q: %v", nodeURL, err)
+			normalized = nodeURL
+		}
+	}
 	return &Client{
-		NodeURL: nodeURL,
+		NodeURL:    normalized,
+		Timeout:    timeout,
+		Username:   os.Getenv("NIMIQ_RPC_USER"),
+		Password:   os.Getenv("NIMIQ_RPC_PASSWORD"),
+		httpClient: &http.Client{Timeout: timeout, Transport: newTransport()},
+	}
+}
+
+// normalizeNodeURL makes node URLs consistent: it defaults the scheme to http, keeps any
+// path prefix (e.g. a gateway's /nimiq/rpc) and strips trailing slashes from it
+func normalizeNodeURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", parsed.Scheme)
 	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("missing host")
+	}
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String(), nil
 }
 
 // query makes a generic RPC call to the Nimiq node
 func (c *Client) query(method string, params interface{}) (json.RawMessage, error) {
+	// fail records the error category for the method before returning the error
+	fail := func(category string, err error) (json.RawMessage, error) {
+		setLastError(method, category)
+		return nil, err
+	}
+
 	requestBody, err := json.Marshal(map[string]interface{}{
 		"jsonrpc": "2.0",
 		"method":  method,
 		"params":  params,
 		"id":      1,
 	})
+	if err != nil {
+		return fail("encode", err)
+	}
+	if logging.DebugEnabled() {
+		logging.Debugf("RPC request %s params=%s", method, redactParams(method, params))
+	}
+
+	release := c.acquire()
+	defer release()
+	resp, err := c.post(requestBody)
+	if err != nil {
+		c.setEndpointHealth(false)
+		return fail("transport", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.setEndpointHealth(false)
+		return fail("transport", err)
+	}
+	logging.Debugf("RPC response %s: %s", method, body)
+	if resp.StatusCode == http.StatusOK && len(bytes.TrimSpace(body)) == 0 {
+		return fail("empty", fmt.Errorf("%w from %s", ErrEmptyResponse, method))
+	}
+
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(body, &result); err != nil {
+		c.setEndpointHealth(false)
+		if resp.StatusCode != http.StatusOK {
+			return fail("http", &HTTPStatusError{StatusCode: resp.StatusCode, Status: resp.Status})
+		}
+		return fail("decode", fmt.Errorf("%w: %v", ErrInvalidResponse, err))
+	}
+	// Any JSON-RPC answer, including an RPC error, means the endpoint itself is healthy
+	c.setEndpointHealth(true)
+
+	if raw, exists := result["error"]; exists && string(raw) != "null" {
+		rpcErr := newRPCError(raw)
+		if IsMethodNotFound(rpcErr) {
+			return fail("method_not_found", rpcErr)
+		}
+		return fail("rpc", rpcErr)
+	}
+	data, exists := result["result"]
+	if !exists {
+		return fail("empty", fmt.Errorf("%w from %s", ErrEmptyResponse, method))
+	}
+
+	clearLastError(method)
+	return data, nil
+}
+
+// Retry policy of the read-only getters
+const (
+	queryRetryAttempts = 3
+	queryRetryBackoff  = 500 * time.Millisecond
+)
+
+// queryWithRetry runs query up to attempts times, doubling backoff with up to 50% jitter between
+// attempts. Only transient transport failures are retried, RPC errors are returned right away.
+// It must only be used for read-only methods, since a repeated request may already have reached the node.
+func (c *Client) queryWithRetry(method string, params interface{}, attempts int, backoff time.Duration) (json.RawMessage, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := c.query(method, params)
+		if err == nil || attempt >= attempts || !isTransientError(err) {
+			return result, err
+		}
+		delay := backoff + time.Duration(rand.Int63n(int64(backoff)/2+1))
+		logging.Debugf("RPC %s attempt %d/%d failed: %v. Retrying in %s", method, attempt, attempts, err, delay)
+		prometheus.RPCRetriesCounter.WithLabelValues(method).Inc()
+		time.Sleep(delay)
+		backoff *= 2
+	}
+}
+
+// isTransientError reports whether err is a transport failure that may succeed when repeated:
+// a failed connection, a timeout, an empty answer or a gateway that is temporarily unavailable
+func isTransientError(err error) bool {
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	if errors.Is(err, ErrEmptyResponse) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// post sends a JSON-RPC request body to the node
+func (c *Client) post(requestBody []byte) (*http.Response, error) {
+	endpoint := c.NodeURL
+	if c.socketPath != "" {
+		endpoint = "http://unix/" // The host is ignored, the transport always dials the socket
+	}
+	httpClient := c.httpClient
+	if httpClient == nil {
+		// Clients not built by NewClient still honor their Timeout
+		httpClient = &http.Client{Timeout: c.Timeout}
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(requestBody))
 	if err != nil {
 		return nil, err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	// Setting Accept-Encoding ourselves turns off the transport's transparent decompression,
+	// so gzip responses are decoded below
+	req.Header.Set("Accept-Encoding", "gzip")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		reader, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("invalid gzip response: %w", err)
+		}
+		resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+	}
+	return resp, nil
+}
+
+// gzipBody decompresses a response body and closes both the decompressor and the underlying body
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
 
-	resp, err := http.Post(c.NodeURL, "application/json", bytes.NewBuffer(requestBody))
+// secretParams lists the positions of secret parameters per RPC method, which must never be logged
+var secretParams = map[string][]int{
+	"importRawKey":                       {0, 1}, // private key, passphrase
+	"unlockAccount":                      {1},    // passphrase
+	"sendNewValidatorTransaction":        {2, 3}, // signing and voting secret keys
+	"createNewValidatorTransaction":      {2, 3}, // signing and voting secret keys
+	"sendReactivateValidatorTransaction": {2},    // signing secret key
+}
+
+// redactParams renders params as JSON with the method's secret parameters replaced
+func redactParams(method string, params interface{}) string {
+	if list, ok := params.([]interface{}); ok {
+		redacted := make([]interface{}, len(list))
+		copy(redacted, list)
+		for _, index := range secretParams[method] {
+			if index < len(redacted) {
+				redacted[index] = "[REDACTED]"
+			}
+		}
+		params = redacted
+	}
+
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "<unencodable params>"
+	}
+	return string(encoded)
+}
+
+// setLastError exposes the category of the latest failure of an RPC method
+func setLastError(method, category string) {
+	prometheus.RPCLastErrorGauge.DeletePartialMatch(map[string]string{"method": method})
+	prometheus.RPCLastErrorGauge.WithLabelValues(method, category).Set(1)
+}
+
+// setEndpointHealth exposes whether the endpoint answered, labeled without any credentials in the URL
+func (c *Client) setEndpointHealth(healthy bool) {
+	endpoint := c.NodeURL
+	if parsed, err := url.Parse(c.NodeURL); err == nil {
+		parsed.User = nil
+		endpoint = parsed.String()
+	}
+	if !healthy {
+		prometheus.RPCEndpointHealthyGauge.WithLabelValues(endpoint).Set(0)
+		return
+	}
+	prometheus.RPCEndpointHealthyGauge.WithLabelValues(endpoint).Set(1)
+	prometheus.RPCEndpointLastSuccessGauge.WithLabelValues(endpoint).SetToCurrentTime()
+}
+
+// clearLastError removes the recorded failure once the RPC method succeeds again
+func clearLastError(method string) {
+	prometheus.RPCLastErrorGauge.DeletePartialMatch(map[string]string{"method": method})
+}
+
+// BatchRequest is a single call within a batched JSON-RPC request
+type BatchRequest struct {
+	Method string
+	Params interface{}
+}
+
+// BatchQuery sends several RPC calls as one JSON-RPC 2.0 batch and returns the results in request
+// order. It fails as a whole when any of the calls returns an RPC error.
+func (c *Client) BatchQuery(requests []BatchRequest) ([]json.RawMessage, error) {
+	results, errs, err := c.batchExchange(requests)
 	if err != nil {
 		return nil, err
 	}
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// batchExchange sends a JSON-RPC 2.0 batch and returns the result and RPC error of every call in
+// request order, so callers can tolerate some of the calls failing
+func (c *Client) batchExchange(requests []BatchRequest) ([]json.RawMessage, []error, error) {
+	batch := make([]map[string]interface{}, len(requests))
+	for i, request := range requests {
+		batch[i] = map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  request.Method,
+			"params":  request.Params,
+			"id":      i + 1,
+		}
+	}
+
+	requestBody, err := json.Marshal(batch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release := c.acquire()
+	defer release()
+	resp, err := c.post(requestBody)
+	if err != nil {
+		c.setEndpointHealth(false)
+		return nil, nil, err
+	}
 	defer resp.Body.Close()
 
-	var result map[string]json.RawMessage
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	var responses []struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  json.RawMessage `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&responses); err != nil {
+		c.setEndpointHealth(false)
+		return nil, nil, err
+	}
+	c.setEndpointHealth(true)
+
+	// Responses may arrive in any order, so match them back by id
+	results := make([]json.RawMessage, len(requests))
+	errs := make([]error, len(requests))
+	for _, response := range responses {
+		if response.ID < 1 || response.ID > len(requests) {
+			return nil, nil, fmt.Errorf("unexpected response id %d in batch", response.ID)
+		}
+		if response.Error != nil && string(response.Error) != "null" {
+			errs[response.ID-1] = fmt.Errorf("%s: %w", requests[response.ID-1].Method, newRPCError(response.Error))
+			continue
+		}
+		results[response.ID-1] = response.Result
+	}
+	for i, result := range results {
+		if result == nil && errs[i] == nil {
+			return nil, nil, fmt.Errorf("missing response for %s in batch", requests[i].Method)
+		}
+	}
+
+	return results, errs, nil
+}
+
+// ValidatorSnapshot is the state of a validator address fetched in a single request
+type ValidatorSnapshot struct {
+	Balance     int64             // Account balance in Luna
+	BlockNumber int64             // Head block number when the snapshot was taken
+	Validator   *ValidatorDetails // nil when the address is not a validator
+}
+
+// FetchValidatorSnapshot fetches the balance, head block number and validator details of an
+// address with one batched request instead of three round trips
+func (c *Client) FetchValidatorSnapshot(address string) (*ValidatorSnapshot, error) {
+	results, errs, err := c.batchExchange([]BatchRequest{
+		{Method: "getAccountByAddress", Params: []interface{}{address}},
+		{Method: "getBlockNumber", Params: []interface{}{}},
+		{Method: "getValidatorByAddress", Params: []interface{}{address}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+	if errs[1] != nil {
+		return nil, errs[1]
+	}
+	if errs[2] != nil && !isValidatorNotFoundError(errs[2]) {
+		return nil, errs[2]
+	}
+
+	var accountResult struct {
+		Data struct {
+			Balance int64 `json:"balance"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(results[0], &accountResult); err != nil {
 		return nil, err
 	}
+	var blockNumberResult struct {
+		Data int64 `json:"data"`
+	}
+	if err := json.Unmarshal(results[1], &blockNumberResult); err != nil {
+		return nil, err
+	}
+	snapshot := &ValidatorSnapshot{Balance: accountResult.Data.Balance, BlockNumber: blockNumberResult.Data}
+	if errs[2] == nil {
+		// A null validator means the address is not a validator, as in GetValidatorByAddress
+		var validatorResult struct {
+			Data *ValidatorDetails `json:"data"`
+		}
+		if err := json.Unmarshal(results[2], &validatorResult); err != nil {
+			return nil, err
+		}
+		snapshot.Validator = validatorResult.Data
+	}
 
-	if err, exists := result["error"]; exists {
-		return nil, fmt.Errorf("RPC error: %s", err)
+	return snapshot, nil
+}
+
+// Ping verifies that the endpoint answers JSON-RPC calls, explaining the likely misconfiguration when it does not
+func (c *Client) Ping() error {
+	_, err := c.query("isConsensusEstablished", []interface{}{})
+	if err == nil {
+		return nil
 	}
 
-	return result["result"], nil
+	var statusErr *HTTPStatusError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &statusErr):
+		return fmt.Errorf("endpoint %s returned HTTP %d, check the RPC path or API version: %w", c.NodeURL, statusErr.StatusCode, err)
+	case errors.Is(err, ErrInvalidResponse):
+		return fmt.Errorf("endpoint %s did not answer with JSON-RPC, check that the URL points at the node RPC: %w", c.NodeURL, err)
+	case IsMethodNotFound(err):
+		return fmt.Errorf("endpoint %s does not support isConsensusEstablished, check the RPC version: %w", c.NodeURL, err)
+	case errors.As(err, &urlErr):
+		return fmt.Errorf("cannot reach node at %s: %w", c.NodeURL, err)
+	}
+	return fmt.Errorf("node at %s failed the startup check: %w", c.NodeURL, err)
 }
 
 // GetConsensusState retrieves the consensus state from the Nimiq node
@@ -76,7 +673,7 @@ func (c *Client) IsConsensusEstablished() (bool, error) {
 
 // GetEpochNumber retrieves the current epoch number from the Nimiq node
 func (c *Client) GetEpochNumber() (int, error) {
-	result, err := c.query("getEpochNumber", []interface{}{})
+	result, err := c.queryWithRetry("getEpochNumber", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
 	if err != nil {
 		return 0, err
 	}
@@ -91,9 +688,63 @@ func (c *Client) GetEpochNumber() (int, error) {
 	return epochResult.Data, nil
 }
 
+// GetElectionBlockOf retrieves the number of the election block that ends the given epoch
+func (c *Client) GetElectionBlockOf(epoch int) (int64, error) {
+	result, err := c.queryWithRetry("getElectionBlockOf", []interface{}{epoch}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return 0, err
+	}
+
+	var blockResult struct {
+		Data int64 `json:"data"`
+	}
+	if err := json.Unmarshal(result, &blockResult); err != nil {
+		return 0, err
+	}
+
+	return blockResult.Data, nil
+}
+
+// GetInherentsByBlockNumber retrieves the inherents (rewards, penalties, jailings) applied in a block
+func (c *Client) GetInherentsByBlockNumber(blockNumber int64) ([]Inherent, error) {
+	result, err := c.queryWithRetry("getInherentsByBlockNumber", []interface{}{blockNumber}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var inherentsResult struct {
+		Data []Inherent `json:"data"`
+	}
+	if err := json.Unmarshal(result, &inherentsResult); err != nil {
+		return nil, err
+	}
+
+	return inherentsResult.Data, nil
+}
+
+// GetBlockByNumber retrieves a block without its transactions
+func (c *Client) GetBlockByNumber(blockNumber int64) (*Block, error) {
+	result, err := c.queryWithRetry("getBlockByNumber", []interface{}{blockNumber, false}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var blockResult struct {
+		Data *Block `json:"data"`
+	}
+	if err := json.Unmarshal(result, &blockResult); err != nil {
+		return nil, err
+	}
+	if blockResult.Data == nil {
+		return nil, fmt.Errorf("block %d not found", blockNumber)
+	}
+
+	return blockResult.Data, nil
+}
+
 // GetAddress retrieves the validator's address from the Nimiq node
 func (c *Client) GetAddress() (string, error) {
-	result, err := c.query("getAddress", []interface{}{})
+	result, err := c.queryWithRetry("getAddress", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
 	if err != nil {
 		return "", err
 	}
@@ -108,9 +759,26 @@ func (c *Client) GetAddress() (string, error) {
 	return addressResult.Data, nil
 }
 
+// ListAccounts retrieves the addresses of all accounts in the node's wallet
+func (c *Client) ListAccounts() ([]string, error) {
+	result, err := c.queryWithRetry("listAccounts", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var accountsResult struct {
+		Data []string `json:"data"`
+	}
+	if err := json.Unmarshal(result, &accountsResult); err != nil {
+		return nil, err
+	}
+
+	return accountsResult.Data, nil
+}
+
 // GetAccountBalanceByAddress retrieves the account balance for a given address from the Nimiq node
 func (c *Client) GetAccountBalanceByAddress(address string) (int64, error) {
-	result, err := c.query("getAccountByAddress", []interface{}{address})
+	result, err := c.queryWithRetry("getAccountByAddress", []interface{}{address}, queryRetryAttempts, queryRetryBackoff)
 	if err != nil {
 		return 0, err
 	}
@@ -127,9 +795,37 @@ func (c *Client) GetAccountBalanceByAddress(address string) (int64, error) {
 	return accountResult.Data.Balance, nil
 }
 
+// GetAccountBalances retrieves the balances of several addresses in a single batched request
+func (c *Client) GetAccountBalances(addresses []string) (map[string]int64, error) {
+	requests := make([]BatchRequest, len(addresses))
+	for i, address := range addresses {
+		requests[i] = BatchRequest{Method: "getAccountByAddress", Params: []interface{}{address}}
+	}
+
+	results, err := c.BatchQuery(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]int64, len(addresses))
+	for i, result := range results {
+		var accountResult struct {
+			Data struct {
+				Balance int64 `json:"balance"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(result, &accountResult); err != nil {
+			return nil, err
+		}
+		balances[addresses[i]] = accountResult.Data.Balance
+	}
+
+	return balances, nil
+}
+
 // GetTotalStakeByValidatorAddress retrieves the total stake for a validator address
 func (c *Client) GetTotalStakeByValidatorAddress(address string) (int64, error) {
-	result, err := c.query("getStakersByValidatorAddress", []interface{}{address})
+	result, err := c.queryWithRetry("getStakersByValidatorAddress", []interface{}{address}, queryRetryAttempts, queryRetryBackoff)
 	if err != nil {
 		return 0, err
 	}
@@ -152,9 +848,12 @@ func (c *Client) GetTotalStakeByValidatorAddress(address string) (int64, error)
 }
 
 func (c *Client) GetValidatorByAddress(address string) (*ValidatorDetails, error) {
-	result, err := c.query("getValidatorByAddress", []interface{}{address})
+	result, err := c.queryWithRetry("getValidatorByAddress", []interface{}{address}, queryRetryAttempts, queryRetryBackoff)
+	if isValidatorNotFoundError(err) {
+		return nil, fmt.Errorf("%w: %w", ErrValidatorNotFound, err)
+	}
 	if err != nil {
-		return nil, err // RPC error or address is not a validator
+		return nil, err // Transport, decode or other RPC error, the validator may well exist
 	}
 
 	var validatorResult struct {
@@ -163,10 +862,71 @@ func (c *Client) GetValidatorByAddress(address string) (*ValidatorDetails, error
 	if err := json.Unmarshal(result, &validatorResult); err != nil {
 		return nil, err // Error parsing the result
 	}
+	// Some node versions report an unknown validator as a successful response with null data
+	if validatorResult.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrValidatorNotFound, address)
+	}
 
 	return validatorResult.Data, nil
 }
 
+// GetStakerByAddress fetches the stake of an address
+func (c *Client) GetStakerByAddress(address string) (*StakerDetails, error) {
+	result, err := c.queryWithRetry("getStakerByAddress", []interface{}{address}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var stakerResult struct {
+		Data *StakerDetails `json:"data"`
+	}
+	if err := json.Unmarshal(result, &stakerResult); err != nil {
+		return nil, err
+	}
+	if stakerResult.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrStakerNotFound, address)
+	}
+
+	return stakerResult.Data, nil
+}
+
+// GetActiveValidators fetches the validators of the current active set
+func (c *Client) GetActiveValidators() ([]ValidatorDetails, error) {
+	result, err := c.queryWithRetry("getActiveValidators", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var activeResult struct {
+		Data []ValidatorDetails `json:"data"`
+	}
+	if err := json.Unmarshal(result, &activeResult); err != nil {
+		return nil, err
+	}
+
+	return activeResult.Data, nil
+}
+
+// GetParkedValidators retrieves the addresses of the validators currently in the parked set
+func (c *Client) GetParkedValidators() ([]string, error) {
+	result, err := c.queryWithRetry("getParkedValidators", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var parkedResult struct {
+		Data struct {
+			BlockNumber int64    `json:"blockNumber"`
+			Validators  []string `json:"validators"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(result, &parkedResult); err != nil {
+		return nil, err
+	}
+
+	return parkedResult.Data.Validators, nil
+}
+
 func (c *Client) ImportRawKey(privateKey, passphrase string) (string, error) {
 	result, err := c.query("importRawKey", []interface{}{privateKey, passphrase})
 	if err != nil {
@@ -188,7 +948,7 @@ func (c *Client) ImportRawKey(privateKey, passphrase string) (string, error) {
 }
 
 func (c *Client) GetCurrentBlockNumber() (int64, error) {
-	result, err := c.query("getBlockNumber", []interface{}{})
+	result, err := c.queryWithRetry("getBlockNumber", []interface{}{}, queryRetryAttempts, queryRetryBackoff)
 	if err != nil {
 		return 0, err
 	}
@@ -242,6 +1002,43 @@ func (c *Client) SendNewValidatorTransaction(senderAddress, validatorAddress, si
 	return txResult.Data, nil
 }
 
+// CreateRawValidatorTransaction builds an unsigned new validator transaction without touching the node wallet
+func (c *Client) CreateRawValidatorTransaction(senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData string, feeInLuna int, validityStartHeight string) (string, error) {
+	params := []interface{}{
+		senderAddress, validatorAddress, signingSecretKey, votingSecretKey, rewardAddress, signalData, feeInLuna, validityStartHeight,
+	}
+	result, err := c.query("createNewValidatorTransaction", params)
+	if err != nil {
+		return "", err
+	}
+
+	var txResult struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(result, &txResult); err != nil {
+		return "", err
+	}
+
+	return txResult.Data, nil
+}
+
+// SignTransaction signs a raw transaction with the key of the signer account
+func (c *Client) SignTransaction(rawTx, signerAddress string) (string, error) {
+	result, err := c.query("signTransaction", []interface{}{rawTx, signerAddress})
+	if err != nil {
+		return "", err
+	}
+
+	var signResult struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(result, &signResult); err != nil {
+		return "", err
+	}
+
+	return signResult.Data, nil
+}
+
 func (c *Client) SendReactivateValidatorTransaction(senderAddress, validatorAddress, signingSecretKey string, feeInLuna int, validityStartHeight string) (string, error) {
 	params := []interface{}{
 		senderAddress, validatorAddress, signingSecretKey, feeInLuna, validityStartHeight,
@@ -261,6 +1058,23 @@ func (c *Client) SendReactivateValidatorTransaction(senderAddress, validatorAddr
 	return txResult.Data, nil
 }
 
+// SendBasicTransaction sends value Luna from the wallet account to the recipient
+func (c *Client) SendBasicTransaction(wallet, recipient string, value int64, feeInLuna int, validityStartHeight string) (string, error) {
+	result, err := c.query("sendBasicTransaction", []interface{}{wallet, recipient, value, feeInLuna, validityStartHeight})
+	if err != nil {
+		return "", err
+	}
+
+	var txResult struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(result, &txResult); err != nil {
+		return "", err
+	}
+
+	return txResult.Data, nil
+}
+
 func (c *Client) SendRawTransaction(rawTx string) (string, error) {
 	result, err := c.query("sendRawTransaction", []interface{}{rawTx})
 	if err != nil {
@@ -277,12 +1091,69 @@ func (c *Client) SendRawTransaction(rawTx string) (string, error) {
 	return sendResult.Data, nil
 }
 
+// GetTransactionByHash retrieves a transaction by its hash, BlockNumber is 0 while it is still pending
+func (c *Client) GetTransactionByHash(hash string) (*Transaction, error) {
+	result, err := c.queryWithRetry("getTransactionByHash", []interface{}{hash}, queryRetryAttempts, queryRetryBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	var txResult struct {
+		Data *Transaction `json:"data"`
+	}
+	if err := json.Unmarshal(result, &txResult); err != nil {
+		return nil, err
+	}
+	if txResult.Data == nil {
+		return nil, fmt.Errorf("transaction %s not found", hash)
+	}
+
+	return txResult.Data, nil
+}
+
+// Inherent struct to hold a parsed inherent, Target and Value are set for rewards
+type Inherent struct {
+	Type             string `json:"type"`
+	BlockNumber      int64  `json:"blockNumber"`
+	ValidatorAddress string `json:"validatorAddress,omitempty"`
+	Target           string `json:"target,omitempty"`
+	Value            int64  `json:"value,omitempty"`
+}
+
+// Block holds the header fields of a block we use, Timestamp is in milliseconds since the Unix epoch
+type Block struct {
+	Number    int64  `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// StakerDetails is the stake of an address, all balances in Luna
+type StakerDetails struct {
+	Address         string  `json:"address"`
+	Balance         int64   `json:"balance"`         // Active stake
+	Delegation      *string `json:"delegation"`      // Validator the stake is delegated to, nil when undelegated
+	InactiveBalance int64   `json:"inactiveBalance"` // Stake being deactivated
+	RetiredBalance  int64   `json:"retiredBalance"`  // Stake that can be withdrawn
+}
+
+// Transaction struct to hold the parsed transaction information
+type Transaction struct {
+	Hash          string `json:"hash"`
+	BlockNumber   int64  `json:"blockNumber"`
+	Confirmations int64  `json:"confirmations"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Value         int64  `json:"value"`
+	Fee           int64  `json:"fee"`
+}
+
 // ValidatorDetails struct to hold the parsed validator information
 type ValidatorDetails struct {
 	Address        string `json:"address"`
-	Balance        int64  `json:"balance"`
+	Balance        *int64 `json:"balance"`           // nil when the node returns a null balance during transitions
+	Deposit        *int64 `json:"deposit,omitempty"` // Locked validator deposit, only reported by nodes that separate it from delegated stake
 	NumStakers     int    `json:"numStakers"`
-	InactivityFlag *int   `json:"inactivityFlag,omitempty"`
+	InactivityFlag *int   `json:"inactivityFlag,omitempty"` // Block the validator is inactive from, nil while active
 	Retired        bool   `json:"retired"`
 	JailedFrom     *int   `json:"jailedFrom,omitempty"`
 }
@@ -0,0 +1,182 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// fixtureExchange is one recorded RPC request and response, stored as a line of JSON in a fixture file
+type fixtureExchange struct {
+	Request    json.RawMessage `json:"request"`
+	StatusCode int             `json:"status_code"`
+	Response   string          `json:"response"`
+}
+
+// RecordingTransport passes requests on to the node and appends every request/response pair
+// to a fixture file, which ReplayTransport can serve later without a node. Secret parameters
+// such as private keys and passphrases are redacted before a request is stored.
+type RecordingTransport struct {
+	Base http.RoundTripper
+	Path string
+
+	mu sync.Mutex
+}
+
+// NewRecordingTransport records the exchanges made through base to the fixture file at path
+func NewRecordingTransport(base http.RoundTripper, path string) *RecordingTransport {
+	return &RecordingTransport{Base: base, Path: path}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Let the transport negotiate and decode compression itself so the fixture stays readable
+	req = req.Clone(req.Context())
+	req.Header.Del("Accept-Encoding")
+	req.Body = io.NopCloser(bytes.NewReader(requestBody))
+
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted, err := redactRequest(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error recording RPC request: %w", err)
+	}
+	line, err := json.Marshal(fixtureExchange{Request: redacted, StatusCode: resp.StatusCode, Response: string(responseBody)})
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	file, err := os.OpenFile(t.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening RPC fixture: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("error recording RPC exchange: %w", err)
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport, so Client.Close keeps working while recording
+func (t *RecordingTransport) CloseIdleConnections() {
+	if closer, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// ReplayTransport answers requests from a fixture file instead of a node. Identical requests get
+// their recorded responses in order, the last one is repeated once they are used up.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	responses map[string][]fixtureExchange
+}
+
+// NewReplayTransport loads the fixture file at path
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	transport := &ReplayTransport{responses: make(map[string][]fixtureExchange)}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16*1024*1024)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var exchange fixtureExchange
+		if err := json.Unmarshal(scanner.Bytes(), &exchange); err != nil {
+			return nil, fmt.Errorf("error parsing RPC fixture %s: %w", path, err)
+		}
+		// Redacting again keeps fixtures recorded before redaction matchable
+		key, err := redactRequest(exchange.Request)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing RPC fixture %s: %w", path, err)
+		}
+		transport.responses[string(key)] = append(transport.responses[string(key)], exchange)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return transport, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Fixtures hold redacted requests, so requests are matched on their redacted form
+	key, err := redactRequest(requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	recorded := t.responses[string(key)]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded response for RPC request %s", key)
+	}
+	exchange := recorded[0]
+	if len(recorded) > 1 {
+		t.responses[string(key)] = recorded[1:]
+	}
+	return &http.Response{
+		StatusCode: exchange.StatusCode,
+		Status:     fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(exchange.Response)),
+		Request:    req,
+	}, nil
+}
+
+// redactRequest returns the JSON-RPC request body in compact form with the method's secret parameters redacted
+func redactRequest(body []byte) (json.RawMessage, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var request map[string]interface{}
+	if err := decoder.Decode(&request); err != nil {
+		return nil, err
+	}
+	if method, ok := request["method"].(string); ok {
+		if params, ok := request["params"]; ok {
+			request["params"] = json.RawMessage(redactParams(method, params))
+		}
+	}
+	return json.Marshal(request)
+}
+
+// readBody reads a request or response body completely and replaces it with a fresh reader
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil {
+		return nil, nil
+	}
+	content, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
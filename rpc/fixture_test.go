@@ -0,0 +1,118 @@
+package rpc
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestRecordAndReplay records exchanges with a fake node and replays them without it
+func TestRecordAndReplay(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+	blockNumber := 100
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		blockNumber++
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"data":` + strconv.Itoa(blockNumber) + `}}`))
+	})
+	client.SetTransport(NewRecordingTransport(http.DefaultTransport, fixture))
+	for _, want := range []int64{101, 102} {
+		if got, err := client.GetCurrentBlockNumber(); err != nil || got != want {
+			t.Fatalf("recording GetCurrentBlockNumber = %d, %v, want %d", got, err, want)
+		}
+	}
+
+	transport, err := NewReplayTransport(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := &Client{NodeURL: "http://replay.invalid"}
+	replay.SetTransport(transport)
+	// Responses come back in recorded order, the last one repeating
+	for _, want := range []int64{101, 102, 102} {
+		if got, err := replay.GetCurrentBlockNumber(); err != nil || got != want {
+			t.Fatalf("replayed GetCurrentBlockNumber = %d, %v, want %d", got, err, want)
+		}
+	}
+	if _, err := replay.query("getEpochNumber", []interface{}{}); err == nil {
+		t.Fatal("expected an error for a request missing from the fixture")
+	}
+}
+
+// TestRecordRedactsSecrets checks that key material never reaches a fixture and that replays still match
+func TestRecordRedactsSecrets(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+	client := newTestClient(t, respond(http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":{"data":"NQ07"}}`))
+	client.SetTransport(NewRecordingTransport(http.DefaultTransport, fixture))
+	if _, err := client.query("importRawKey", []interface{}{"deadbeefprivatekey", "passphrase"}); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"deadbeefprivatekey", "passphrase"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("fixture contains %q: %s", secret, content)
+		}
+	}
+
+	transport, err := NewReplayTransport(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay := &Client{NodeURL: "http://replay.invalid"}
+	replay.SetTransport(transport)
+	if got, err := replay.query("importRawKey", []interface{}{"deadbeefprivatekey", "passphrase"}); err != nil || string(got) != `{"data":"NQ07"}` {
+		t.Fatalf("replayed importRawKey = %s, %v", got, err)
+	}
+}
+
+func TestReplayFixture(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+	lines := `{"request":{"id":1,"jsonrpc":"2.0","method":"getEpochNumber","params":[]},"status_code":200,"response":"{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"data\":4}}"}
+
+{"request":{"id":1,"jsonrpc":"2.0","method":"getValidatorByAddress","params":["NQ07"]},"status_code":200,"response":"{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"data\":null}}"}
+{"request":{"id":1,"jsonrpc":"2.0","method":"getBlockNumber","params":[]},"status_code":503,"response":"unavailable"}
+`
+	if err := os.WriteFile(fixture, []byte(lines), 0600); err != nil {
+		t.Fatal(err)
+	}
+	transport, err := NewReplayTransport(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{NodeURL: "http://replay.invalid"}
+	client.SetTransport(transport)
+
+	tests := []struct {
+		method  string
+		params  []interface{}
+		want    string
+		wantErr bool
+	}{
+		{"getEpochNumber", []interface{}{}, `{"data":4}`, false},
+		{"getValidatorByAddress", []interface{}{"NQ07"}, `{"data":null}`, false},
+		{"getValidatorByAddress", []interface{}{"NQ28"}, "", true},
+		{"getBlockNumber", []interface{}{}, "", true},
+	}
+	for _, test := range tests {
+		got, err := client.query(test.method, test.params)
+		if (err != nil) != test.wantErr || string(got) != test.want {
+			t.Errorf("query(%s, %v) = %s, %v, want %s", test.method, test.params, got, err, test.want)
+		}
+	}
+}
+
+func TestReplayMalformedFixture(t *testing.T) {
+	fixture := filepath.Join(t.TempDir(), "fixture.jsonl")
+	if err := os.WriteFile(fixture, []byte("not json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewReplayTransport(fixture); err == nil {
+		t.Fatal("expected an error for a malformed fixture")
+	}
+}